@@ -0,0 +1,200 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"net/netip"
+	"os"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ScanState é o estado salvo de uma varredura em andamento.
+//
+// Como os endereços terminam fora de ordem, o progresso é guardado como
+// Done, a quantidade de endereços concluídos em sequência desde o início, e
+// Extra, os concluídos depois desse ponto. Ao retomar, ambos são pulados.
+type ScanState struct {
+	ScanReport
+	Done  uint64       `json:"done"`
+	Extra []netip.Addr `json:"extra,omitempty"`
+}
+
+// Checkpoint acompanha o progresso da varredura e o salva periodicamente em
+// disco para que ela possa ser retomada.
+type Checkpoint struct {
+	Path     string
+	Interval time.Duration
+
+	mu       sync.Mutex
+	state    ScanState
+	carried  map[netip.Addr]bool   // Extra da execução anterior ainda não alcançados
+	pending  map[netip.Addr]uint64 // entregues por Filter e ainda não concluídos
+	finished map[uint64]netip.Addr // concluídos depois de state.Done, por posição
+	lastSave time.Time
+}
+
+// NewCheckpoint começa uma varredura nova dos alvos informados.
+func NewCheckpoint(path string, targets []string) *Checkpoint {
+	c := &Checkpoint{Path: path, Interval: 5 * time.Second}
+	c.state.Targets = targets
+	c.state.Started = time.Now()
+	c.init()
+	return c
+}
+
+func (c *Checkpoint) init() {
+	c.carried = make(map[netip.Addr]bool, len(c.state.Extra))
+	for _, a := range c.state.Extra {
+		c.carried[a] = true
+	}
+	c.state.Extra = nil
+	c.pending = make(map[netip.Addr]uint64)
+	c.finished = make(map[uint64]netip.Addr)
+}
+
+// LoadCheckpoint retoma a varredura salva em path.
+func LoadCheckpoint(path string) (*Checkpoint, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	c := &Checkpoint{Path: path, Interval: 5 * time.Second}
+	if err := json.Unmarshal(data, &c.state); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	c.init()
+	return c, nil
+}
+
+// Targets retorna os alvos da varredura salva.
+func (c *Checkpoint) Targets() []string {
+	return c.state.Targets
+}
+
+// Progress retorna quantos endereços já foram concluídos.
+func (c *Checkpoint) Progress() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.state.Done + uint64(len(c.finished)) + uint64(len(c.carried))
+}
+
+// Filter percorre os endereços pulando os já concluídos e registra a posição
+// de cada um entregue, para que Record possa avançar o progresso.
+func (c *Checkpoint) Filter(all iter.Seq[netip.Addr]) iter.Seq[netip.Addr] {
+	return func(yield func(netip.Addr) bool) {
+		c.mu.Lock()
+		skip := c.state.Done
+		c.mu.Unlock()
+
+		var pos uint64
+		for addr := range all {
+			pos++
+			if pos <= skip {
+				continue
+			}
+
+			c.mu.Lock()
+			if c.carried[addr] {
+				delete(c.carried, addr)
+				c.finished[pos] = addr
+				c.advance()
+				c.mu.Unlock()
+				continue
+			}
+			c.pending[addr] = pos
+			c.mu.Unlock()
+
+			if !yield(addr) {
+				return
+			}
+		}
+	}
+}
+
+// Record marca o endereço como concluído, guarda o host se estiver ativo e
+// salva o estado se o último salvamento tiver mais de Interval.
+func (c *Checkpoint) Record(r Result) error {
+	c.mu.Lock()
+	if pos, ok := c.pending[r.Addr]; ok {
+		delete(c.pending, r.Addr)
+		c.finished[pos] = r.Addr
+		c.advance()
+	}
+	if r.Up {
+		c.state.Hosts = append(c.state.Hosts, Host{Addr: r.Addr, RTT: r.RTT, Ports: r.Ports})
+	}
+	due := time.Since(c.lastSave) >= c.Interval
+	c.mu.Unlock()
+
+	if due {
+		return c.Save()
+	}
+	return nil
+}
+
+// advance move Done enquanto os próximos endereços estiverem concluídos.
+func (c *Checkpoint) advance() {
+	for {
+		if _, ok := c.finished[c.state.Done+1]; !ok {
+			return
+		}
+		delete(c.finished, c.state.Done+1)
+		c.state.Done++
+	}
+}
+
+// Report retorna o relatório com todos os hosts encontrados até agora,
+// inclusive os de execuções anteriores.
+func (c *Checkpoint) Report(complete bool) ScanReport {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	r := c.state.ScanReport
+	r.Hosts = slices.Clone(r.Hosts)
+	r.Finished = time.Now()
+	r.Complete = complete
+	r.SortHosts()
+	return r
+}
+
+// Save grava o estado atual de forma atômica. Com Path vazio o checkpoint só
+// acumula os resultados em memória e nada é gravado.
+func (c *Checkpoint) Save() error {
+	if c.Path == "" {
+		return nil
+	}
+
+	c.mu.Lock()
+	state := c.state
+	state.Hosts = slices.Clone(state.Hosts)
+	for addr := range c.carried {
+		state.Extra = append(state.Extra, addr)
+	}
+	for _, addr := range c.finished {
+		state.Extra = append(state.Extra, addr)
+	}
+	c.lastSave = time.Now()
+	c.mu.Unlock()
+
+	return writeFileAtomic(c.Path, func(w io.Writer) error {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(state)
+	})
+}
+
+// Remove apaga o estado salvo, usado quando a varredura termina.
+func (c *Checkpoint) Remove() error {
+	if c.Path == "" {
+		return nil
+	}
+	err := os.Remove(c.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"iter"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+// testTargets são os oito endereços de 10.0.0.1 a 10.0.0.8.
+var testTargets = []string{"10.0.0.1-10.0.0.8"}
+
+func allTargets(t *testing.T) iter.Seq[netip.Addr] {
+	t.Helper()
+	targets, err := ParseTargets(testTargets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return targets.All()
+}
+
+// hostN é o endereço 10.0.0.n.
+func hostN(n int) netip.Addr {
+	return netip.AddrFrom4([4]byte{10, 0, 0, byte(n)})
+}
+
+func hostsN(ns ...int) []netip.Addr {
+	addrs := make([]netip.Addr, len(ns))
+	for i, n := range ns {
+		addrs[i] = hostN(n)
+	}
+	return addrs
+}
+
+func hostAddrs(r ScanReport) []netip.Addr {
+	var addrs []netip.Addr
+	for _, h := range r.Hosts {
+		addrs = append(addrs, h.Addr)
+	}
+	return addrs
+}
+
+func TestCheckpointResume(t *testing.T) {
+	tests := []struct {
+		name      string
+		delivered int   // endereços que o primeiro Filter chegou a entregar
+		done      []int // concluídos, na ordem em que terminaram
+		remaining []int // entregues pelo Filter ao retomar
+	}{
+		{"nada concluído", 8, nil, []int{1, 2, 3, 4, 5, 6, 7, 8}},
+		{"em ordem", 8, []int{1, 2, 3}, []int{4, 5, 6, 7, 8}},
+		// 4 e 6 terminaram antes de 3: ficam em Extra e também são pulados
+		{"fora de ordem", 8, []int{2, 1, 4, 6}, []int{3, 5, 7, 8}},
+		// Entregues e interrompidos sem resultado são sondados de novo
+		{"interrompida", 5, []int{1, 3}, []int{2, 4, 5, 6, 7, 8}},
+		{"tudo concluído", 8, []int{8, 7, 6, 5, 4, 3, 2, 1}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "estado.json")
+			c := NewCheckpoint(path, testTargets)
+			c.Interval = time.Hour
+
+			var delivered int
+			for range c.Filter(allTargets(t)) {
+				if delivered++; delivered == tt.delivered {
+					break
+				}
+			}
+			var up []netip.Addr
+			for i, n := range tt.done {
+				// Metade dos concluídos está ativa
+				r := Result{Addr: hostN(n), Up: i%2 == 0}
+				if r.Up {
+					up = append(up, r.Addr)
+				}
+				if err := c.Record(r); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := c.Save(); err != nil {
+				t.Fatal(err)
+			}
+
+			resumed, err := LoadCheckpoint(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(resumed.Targets(), testTargets) {
+				t.Errorf("Targets = %v, quer %v", resumed.Targets(), testTargets)
+			}
+			if got := resumed.Progress(); got != uint64(len(tt.done)) {
+				t.Errorf("Progress = %d, quer %d", got, len(tt.done))
+			}
+			got := slices.Collect(resumed.Filter(allTargets(t)))
+			if want := hostsN(tt.remaining...); !slices.Equal(got, want) {
+				t.Errorf("Filter ao retomar = %v, quer %v", got, want)
+			}
+			// Os pulados continuam contados, agora em Done
+			if got := resumed.Progress(); got != uint64(len(tt.done)) {
+				t.Errorf("Progress depois do Filter = %d, quer %d", got, len(tt.done))
+			}
+			slices.SortFunc(up, netip.Addr.Compare)
+			if got := hostAddrs(resumed.Report(false)); !slices.Equal(got, up) {
+				t.Errorf("hosts = %v, quer %v", got, up)
+			}
+		})
+	}
+}
+
+func TestCheckpointRecordSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "estado.json")
+	c := NewCheckpoint(path, testTargets)
+	for range c.Filter(allTargets(t)) {
+	}
+
+	// O primeiro Record já grava; o seguinte espera Interval
+	if err := c.Record(Result{Addr: hostN(1), Up: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Record(Result{Addr: hostN(2), Up: true}); err != nil {
+		t.Fatal(err)
+	}
+	saved, err := LoadCheckpoint(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := saved.Progress(); got != 1 {
+		t.Errorf("Progress gravado = %d, quer 1", got)
+	}
+
+	c.Interval = 0
+	if err := c.Record(Result{Addr: hostN(3)}); err != nil {
+		t.Fatal(err)
+	}
+	if saved, err = LoadCheckpoint(path); err != nil {
+		t.Fatal(err)
+	}
+	if got := saved.Progress(); got != 3 {
+		t.Errorf("Progress gravado = %d, quer 3", got)
+	}
+	// Um endereço que o Filter não entregou não muda o progresso
+	if err := c.Record(Result{Addr: hostN(99)}); err != nil {
+		t.Fatal(err)
+	}
+	if got := c.Progress(); got != 3 {
+		t.Errorf("Progress = %d, quer 3", got)
+	}
+
+	if err := c.Remove(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Remove deixou o estado: %v", err)
+	}
+	if err := c.Remove(); err != nil {
+		t.Errorf("Remove sem arquivo = %v", err)
+	}
+}
+
+func TestCheckpointNoPath(t *testing.T) {
+	dir := t.TempDir()
+	c := NewCheckpoint("", testTargets)
+	for range c.Filter(allTargets(t)) {
+	}
+	if err := c.Record(Result{Addr: hostN(1), Up: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("arquivos gravados sem Path: %v", entries)
+	}
+	if got := hostAddrs(c.Report(true)); !slices.Equal(got, hostsN(1)) {
+		t.Errorf("hosts = %v, quer 10.0.0.1", got)
+	}
+}
+
+func TestLoadCheckpointInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "estado.json")
+	if _, err := LoadCheckpoint(path); !os.IsNotExist(err) {
+		t.Errorf("LoadCheckpoint sem arquivo = %v", err)
+	}
+	if err := os.WriteFile(path, []byte(`{"done": "muitos"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadCheckpoint(path); err == nil {
+		t.Error("LoadCheckpoint aceitou JSON inválido")
+	}
+}
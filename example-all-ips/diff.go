@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/netip"
+	"sort"
+)
+
+// Change é uma diferença entre duas varreduras: um host (Port zero) ou uma
+// porta que apareceu (Added) ou desapareceu.
+type Change struct {
+	Added bool
+	Addr  netip.Addr
+	Port  uint16
+}
+
+func (c Change) String() string {
+	sign := "-"
+	if c.Added {
+		sign = "+"
+	}
+	if c.Port == 0 {
+		return fmt.Sprintf("%s %s", sign, c.Addr)
+	}
+	return fmt.Sprintf("%s %s %d/tcp", sign, c.Addr, c.Port)
+}
+
+// DiffReports compara duas varreduras. As portas de um host que apareceu ou
+// desapareceu por inteiro não são listadas separadamente.
+func DiffReports(old, new ScanReport) []Change {
+	oldHosts := portsByHost(old)
+	newHosts := portsByHost(new)
+
+	var changes []Change
+	for addr, ports := range newHosts {
+		before, ok := oldHosts[addr]
+		if !ok {
+			changes = append(changes, Change{Added: true, Addr: addr})
+			continue
+		}
+		for port := range ports {
+			if !before[port] {
+				changes = append(changes, Change{Added: true, Addr: addr, Port: port})
+			}
+		}
+	}
+	for addr, ports := range oldHosts {
+		after, ok := newHosts[addr]
+		if !ok {
+			changes = append(changes, Change{Addr: addr})
+			continue
+		}
+		for port := range ports {
+			if !after[port] {
+				changes = append(changes, Change{Addr: addr, Port: port})
+			}
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		a, b := changes[i], changes[j]
+		if a.Addr != b.Addr {
+			return a.Addr.Less(b.Addr)
+		}
+		return a.Port < b.Port
+	})
+	return changes
+}
+
+func portsByHost(r ScanReport) map[netip.Addr]map[uint16]bool {
+	hosts := make(map[netip.Addr]map[uint16]bool, len(r.Hosts))
+	for _, h := range r.Hosts {
+		ports := hosts[h.Addr]
+		if ports == nil {
+			ports = make(map[uint16]bool)
+			hosts[h.Addr] = ports
+		}
+		for _, p := range h.Ports {
+			ports[p.Port] = true
+		}
+	}
+	return hosts
+}
+
+// runDiff implementa o subcomando `diff <antigo> <novo>`. Retorna o número
+// de diferenças encontradas.
+func runDiff(w io.Writer, oldPath, newPath string) (int, error) {
+	old, err := ReadReportFile(oldPath)
+	if err != nil {
+		return 0, err
+	}
+	new, err := ReadReportFile(newPath)
+	if err != nil {
+		return 0, err
+	}
+
+	changes := DiffReports(old, new)
+	for _, c := range changes {
+		fmt.Fprintln(w, c)
+	}
+	return len(changes), nil
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// report monta um relatório a partir de hosts no formato "n:porta,porta".
+func report(hosts ...string) ScanReport {
+	var r ScanReport
+	for _, h := range hosts {
+		n, ports, _ := strings.Cut(h, ":")
+		host := Host{Addr: hostN(mustAtoi(n))}
+		if ports != "" {
+			for _, p := range strings.Split(ports, ",") {
+				host.Ports = append(host.Ports, PortResult{Port: uint16(mustAtoi(p))})
+			}
+		}
+		r.Hosts = append(r.Hosts, host)
+	}
+	return r
+}
+
+func mustAtoi(s string) int {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		panic(err)
+	}
+	return n
+}
+
+func TestDiffReports(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new ScanReport
+		want     string
+	}{
+		{"iguais", report("1:22,80", "2"), report("2", "1:80,22"), ""},
+		{"vazias", report(), report(), ""},
+		// As portas de um host novo ou sumido não aparecem separadas
+		{"host novo", report("1"), report("1", "2:22,80"), "+ 10.0.0.2"},
+		{"host sumido", report("1:22", "2"), report("2"), "- 10.0.0.1"},
+		{"portas", report("1:22,80"), report("1:22,443"), "- 10.0.0.1 80/tcp|+ 10.0.0.1 443/tcp"},
+		{
+			"ordenadas por endereço e porta",
+			report("10:80", "2:22", "3"),
+			report("3:8080,21", "10", "4"),
+			"- 10.0.0.2|+ 10.0.0.3 21/tcp|+ 10.0.0.3 8080/tcp|+ 10.0.0.4|- 10.0.0.10 80/tcp",
+		},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, c := range DiffReports(tt.old, tt.new) {
+			got = append(got, c.String())
+		}
+		if s := strings.Join(got, "|"); s != tt.want {
+			t.Errorf("%s: DiffReports = %s, quer %s", tt.name, s, tt.want)
+		}
+	}
+}
+
+func TestRunDiff(t *testing.T) {
+	dir := t.TempDir()
+	oldPath := filepath.Join(dir, "antes.json")
+	newPath := filepath.Join(dir, "depois.txt")
+	if err := WriteReportFile(oldPath, report("1:22", "2")); err != nil {
+		t.Fatal(err)
+	}
+	// Formatos diferentes podem ser comparados
+	if err := os.WriteFile(newPath, []byte("10.0.0.1\n10.0.0.1 22/tcp\n10.0.0.1 80/tcp\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var b strings.Builder
+	n, err := runDiff(&b, oldPath, newPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "+ 10.0.0.1 80/tcp\n- 10.0.0.2\n"; n != 2 || b.String() != want {
+		t.Errorf("runDiff = %d, %q; quer 2, %q", n, b.String(), want)
+	}
+	if _, err := runDiff(&b, oldPath, filepath.Join(dir, "nao-existe.json")); err == nil {
+		t.Error("runDiff aceitou arquivo inexistente")
+	}
+}
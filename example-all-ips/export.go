@@ -0,0 +1,242 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Host é um host ativo no resultado de uma varredura.
+type Host struct {
+	Addr  netip.Addr    `json:"addr"`
+	RTT   time.Duration `json:"rtt"`
+	Ports []PortResult  `json:"ports,omitempty"`
+}
+
+// ScanReport é o resultado de uma varredura. Complete é falso quando a
+// varredura foi interrompida antes de sondar todos os endereços.
+type ScanReport struct {
+	Targets  []string  `json:"targets"`
+	Started  time.Time `json:"started"`
+	Finished time.Time `json:"finished"`
+	Complete bool      `json:"complete"`
+	Hosts    []Host    `json:"hosts"`
+}
+
+// SortHosts ordena os hosts por endereço.
+func (r *ScanReport) SortHosts() {
+	sort.Slice(r.Hosts, func(i, j int) bool { return r.Hosts[i].Addr.Less(r.Hosts[j].Addr) })
+}
+
+// formatFromPath escolhe o formato pela extensão: .json, .csv ou inventário
+// para qualquer outra.
+func formatFromPath(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return "json"
+	case ".csv":
+		return "csv"
+	default:
+		return "inventory"
+	}
+}
+
+// WriteReportFile grava o relatório no formato indicado pela extensão do
+// arquivo. A escrita é atômica: um arquivo temporário é renomeado no fim.
+func WriteReportFile(path string, r ScanReport) error {
+	return writeFileAtomic(path, func(w io.Writer) error {
+		switch formatFromPath(path) {
+		case "json":
+			return WriteJSON(w, r)
+		case "csv":
+			return WriteCSV(w, r)
+		default:
+			return WriteInventory(w, r)
+		}
+	})
+}
+
+// WriteJSON grava o relatório completo em JSON.
+func WriteJSON(w io.Writer, r ScanReport) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// WriteCSV grava uma linha por porta aberta, ou uma linha com porta vazia
+// para hosts sem portas abertas.
+func WriteCSV(w io.Writer, r ScanReport) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"addr", "rtt_ms", "port", "banner"})
+	for _, h := range r.Hosts {
+		rtt := strconv.FormatFloat(float64(h.RTT)/float64(time.Millisecond), 'f', 3, 64)
+		if len(h.Ports) == 0 {
+			cw.Write([]string{h.Addr.String(), rtt, "", ""})
+		}
+		for _, p := range h.Ports {
+			cw.Write([]string{h.Addr.String(), rtt, strconv.Itoa(int(p.Port)), p.Banner})
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteInventory grava o inventário: uma linha por host e uma por porta
+// aberta, ordenadas, sem tempos de resposta. Duas varreduras da mesma rede
+// podem ser comparadas com diff comum ou com o subcomando diff.
+func WriteInventory(w io.Writer, r ScanReport) error {
+	for _, line := range inventoryLines(r) {
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func inventoryLines(r ScanReport) []string {
+	// Ordena uma cópia: o slice de hosts é de quem chamou
+	r.Hosts = slices.Clone(r.Hosts)
+	r.SortHosts()
+	var lines []string
+	for _, h := range r.Hosts {
+		lines = append(lines, h.Addr.String())
+		for _, p := range h.Ports {
+			lines = append(lines, strings.TrimRight(fmt.Sprintf("%s %d/tcp %s", h.Addr, p.Port, p.Banner), " "))
+		}
+	}
+	return lines
+}
+
+// ReadReportFile lê um relatório em JSON, CSV ou inventário, pela extensão.
+// Dos formatos CSV e inventário só são recuperados hosts, portas e banners.
+func ReadReportFile(path string) (ScanReport, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ScanReport{}, err
+	}
+
+	var r ScanReport
+	switch formatFromPath(path) {
+	case "json":
+		err = json.Unmarshal(data, &r)
+	case "csv":
+		r, err = parseCSV(string(data))
+	default:
+		r, err = parseInventory(string(data))
+	}
+	if err != nil {
+		return ScanReport{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return r, nil
+}
+
+func parseCSV(data string) (ScanReport, error) {
+	records, err := csv.NewReader(strings.NewReader(data)).ReadAll()
+	if err != nil {
+		return ScanReport{}, err
+	}
+
+	var hosts hostSet
+	for i, rec := range records {
+		if i == 0 || len(rec) < 4 {
+			continue
+		}
+		addr, err := netip.ParseAddr(rec[0])
+		if err != nil {
+			return ScanReport{}, fmt.Errorf("linha %d: %w", i+1, err)
+		}
+		h := hosts.get(addr)
+		if rec[2] != "" {
+			port, err := parsePort(rec[2])
+			if err != nil {
+				return ScanReport{}, fmt.Errorf("linha %d: %w", i+1, err)
+			}
+			h.Ports = append(h.Ports, PortResult{Port: port, Banner: rec[3]})
+		}
+	}
+	return ScanReport{Hosts: hosts.list}, nil
+}
+
+func parseInventory(data string) (ScanReport, error) {
+	var hosts hostSet
+	for i, line := range strings.Split(data, "\n") {
+		fields := strings.SplitN(strings.TrimSpace(line), " ", 3)
+		if fields[0] == "" {
+			continue
+		}
+		addr, err := netip.ParseAddr(fields[0])
+		if err != nil {
+			return ScanReport{}, fmt.Errorf("linha %d: %w", i+1, err)
+		}
+		h := hosts.get(addr)
+		if len(fields) == 1 {
+			continue
+		}
+		port, err := parsePort(strings.TrimSuffix(fields[1], "/tcp"))
+		if err != nil {
+			return ScanReport{}, fmt.Errorf("linha %d: %w", i+1, err)
+		}
+		p := PortResult{Port: port}
+		if len(fields) == 3 {
+			p.Banner = fields[2]
+		}
+		h.Ports = append(h.Ports, p)
+	}
+	return ScanReport{Hosts: hosts.list}, nil
+}
+
+// hostSet agrupa linhas de um mesmo host mantendo a ordem de aparição.
+type hostSet struct {
+	list  []Host
+	index map[netip.Addr]int
+}
+
+func (s *hostSet) get(addr netip.Addr) *Host {
+	if s.index == nil {
+		s.index = make(map[netip.Addr]int)
+	}
+	i, ok := s.index[addr]
+	if !ok {
+		i = len(s.list)
+		s.index[addr] = i
+		s.list = append(s.list, Host{Addr: addr})
+	}
+	return &s.list[i]
+}
+
+// writeFileAtomic grava o relatório ou o checkpoint em um temporário no mesmo
+// diretório e o renomeia para path. O Sync antes do rename garante que, após
+// uma queda de energia, path tem o conteúdo antigo ou o novo, e não um
+// arquivo vazio. A permissão 0644 substitui a 0600 do os.CreateTemp, para
+// que o relatório possa ser lido por outros usuários, como um arquivo comum.
+func writeFileAtomic(path string, write func(io.Writer) error) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	err = write(tmp)
+	if err == nil {
+		err = tmp.Chmod(0644)
+	}
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testReport = ScanReport{
+	Targets:  []string{"10.0.0.0/24"},
+	Started:  time.Date(2024, 10, 7, 9, 0, 0, 0, time.UTC),
+	Finished: time.Date(2024, 10, 7, 9, 5, 0, 0, time.UTC),
+	Complete: true,
+	Hosts: []Host{
+		{Addr: hostN(20), RTT: 1500 * time.Microsecond},
+		{Addr: hostN(3), RTT: 2 * time.Millisecond, Ports: []PortResult{
+			{Port: 22, Banner: "SSH-2.0-OpenSSH_9.6"},
+			{Port: 80},
+			{Port: 443, Banner: "a, b \"c\""},
+		}},
+	},
+}
+
+func TestWriteInventory(t *testing.T) {
+	var b strings.Builder
+	if err := WriteInventory(&b, testReport); err != nil {
+		t.Fatal(err)
+	}
+	want := `10.0.0.3
+10.0.0.3 22/tcp SSH-2.0-OpenSSH_9.6
+10.0.0.3 80/tcp
+10.0.0.3 443/tcp a, b "c"
+10.0.0.20
+`
+	if b.String() != want {
+		t.Errorf("WriteInventory =\n%s\nquer\n%s", b.String(), want)
+	}
+}
+
+func TestReportFileRoundTrip(t *testing.T) {
+	before := fmt.Sprint(testReport)
+	// CSV e inventário só guardam hosts, portas e banners
+	hostsOnly := ScanReport{Hosts: []Host{
+		{Addr: hostN(3), Ports: testReport.Hosts[1].Ports},
+		{Addr: hostN(20)},
+	}}
+	csvHosts := ScanReport{Hosts: []Host{
+		{Addr: hostN(20)},
+		{Addr: hostN(3), Ports: testReport.Hosts[1].Ports},
+	}}
+	tests := []struct {
+		file string
+		want ScanReport
+	}{
+		{"relatorio.json", testReport},
+		{"relatorio.JSON", testReport},
+		{"relatorio.csv", csvHosts},
+		{"inventario.txt", hostsOnly},
+		{"inventario", hostsOnly},
+	}
+	for _, tt := range tests {
+		dir := t.TempDir()
+		path := filepath.Join(dir, tt.file)
+		if err := WriteReportFile(path, testReport); err != nil {
+			t.Fatalf("%s: %v", tt.file, err)
+		}
+		got, err := ReadReportFile(path)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.file, err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: relido = %+v, quer %+v", tt.file, got, tt.want)
+		}
+
+		// Só o arquivo final fica no diretório, legível por todos
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(entries) != 1 {
+			t.Errorf("%s: arquivos no diretório = %v", tt.file, entries)
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if perm := info.Mode().Perm(); perm != 0644 {
+			t.Errorf("%s: permissão = %v, quer 0644", tt.file, perm)
+		}
+		// A ordem do inventário não muda o relatório de quem chamou
+		if fmt.Sprint(testReport) != before {
+			t.Fatalf("%s: WriteReportFile alterou o relatório", tt.file)
+		}
+	}
+}
+
+func TestReadReportFileInvalid(t *testing.T) {
+	tests := []struct {
+		file, data string
+	}{
+		{"r.json", `{"hosts": [{"addr": "10.0.0.999"}]}`},
+		{"r.csv", "addr,rtt_ms,port,banner\n10.0.0.1,1.000,http,\n"},
+		{"r.csv", "addr,rtt_ms,port,banner\nroteador,1.000,80,\n"},
+		{"r.csv", "addr,rtt_ms\n10.0.0.1,\"1.000\n"},
+		{"r.txt", "10.0.0.1\n10.0.0.1 70000/tcp\n"},
+		{"r.txt", "roteador 80/tcp\n"},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), tt.file)
+		if err := os.WriteFile(path, []byte(tt.data), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := ReadReportFile(path); err == nil {
+			t.Errorf("ReadReportFile(%s) aceitou %q", tt.file, tt.data)
+		}
+	}
+}
+
+func TestWriteReportFileError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nao-existe", "relatorio.json")
+	if err := WriteReportFile(path, testReport); err == nil {
+		t.Error("WriteReportFile gravou em diretório inexistente")
+	}
+}
@@ -1,35 +1,200 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
-	"os/exec"
-	"runtime"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
 )
 
 func main() {
-	for a := uint8(255); a >= 0; a-- {
-		for b := uint8(255); b >= 0; b-- {
-			for c := uint8(255); c >= 0; c-- {
-				for d := uint8(255); d >= 0; d-- {
-					ip := fmt.Sprintf("%d.%d.%d.%d", a, b, c, d)
-
-					// Comando ping diferente para Windows e Linux/macOS
-					var cmd *exec.Cmd
-					if runtime.GOOS == "windows" {
-						cmd = exec.Command("ping", "-n", "1", ip)
-					} else {
-						cmd = exec.Command("ping", "-c", "1", ip)
-					}
-
-					// Executa o comando ping
-					err := cmd.Run()
-					if err == nil {
-						fmt.Println(ip, "- Host ativo")
-					} else {
-						fmt.Println(ip, "- Host inativo")
-					}
-				}
+	if len(os.Args) > 1 && os.Args[1] == "diff" {
+		if len(os.Args) != 4 {
+			fmt.Println("Uso: all-ips diff <resultado-antigo> <resultado-novo>")
+			os.Exit(2)
+		}
+		n, err := runDiff(os.Stdout, os.Args[2], os.Args[3])
+		if err != nil {
+			fmt.Println("Erro:", err)
+			os.Exit(2)
+		}
+		// Como o diff(1): código 1 quando há diferenças
+		if n > 0 {
+			os.Exit(1)
+		}
+		return
+	}
+
+	file := flag.String("file", "", "arquivo com alvos, um por linha")
+	maxHosts := flag.Uint64("max", 65536, "quantidade máxima de endereços por varredura")
+	workers := flag.Int("workers", 64, "sondagens simultâneas")
+	rate := flag.Float64("rate", 100, "pacotes por segundo em toda a varredura (0 sem limite)")
+	timeout := flag.Duration("timeout", 2*time.Second, "prazo de cada sondagem")
+	verbose := flag.Bool("v", false, "mostra também os hosts inativos")
+	probe := flag.String("probe", "icmp", "estratégias de detecção separadas por vírgula: ping, icmp, icmp-raw, tcp, arp")
+	discoveryPorts := flag.String("discovery-ports", "22,80,443,445,3389", "portas usadas pela estratégia tcp")
+	scanPorts := flag.String("ports", "", "portas verificadas em cada host ativo, ex.: 22,80,8000-8100 (vazio desativa)")
+	portTimeout := flag.Duration("port-timeout", time.Second, "prazo de cada conexão e leitura de banner")
+	portWorkers := flag.Int("port-workers", 16, "conexões simultâneas por host na varredura de portas")
+	banner := flag.Bool("banner", false, "lê o banner dos serviços nas portas abertas")
+	statePath := flag.String("state", "", "arquivo onde o progresso é salvo para retomar a varredura")
+	resume := flag.Bool("resume", false, "retoma a varredura salva em -state")
+	output := flag.String("o", "", "grava o resultado em arquivo: .json, .csv ou inventário (qualquer outra extensão)")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "Uso: all-ips [flags] <alvo>...")
+		fmt.Fprintln(flag.CommandLine.Output(), "     all-ips diff <resultado-antigo> <resultado-novo>")
+		fmt.Fprintln(flag.CommandLine.Output(), "Alvos: 192.168.0.10, 192.168.0.0/24, 10.0.0.1-10.0.0.50, fd00::/120")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if !(*rate >= 0 && *rate <= MaxRate) {
+		fmt.Printf("Erro: -rate deve estar entre 0 e %.0f\n", MaxRate)
+		os.Exit(2)
+	}
+
+	specs := flag.Args()
+	if *file != "" {
+		f, err := os.Open(*file)
+		if err != nil {
+			fmt.Println("Erro:", err)
+			os.Exit(1)
+		}
+		fromFile, err := ReadTargets(f)
+		f.Close()
+		if err != nil {
+			fmt.Println("Erro:", err)
+			os.Exit(1)
+		}
+		specs = append(specs, fromFile...)
+	}
+
+	checkpoint, err := openCheckpoint(*statePath, *resume, specs)
+	if err != nil {
+		fmt.Println("Erro:", err)
+		os.Exit(2)
+	}
+	specs = checkpoint.Targets()
+	if len(specs) == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	targets, err := ParseTargets(specs)
+	if err != nil {
+		fmt.Println("Erro:", err)
+		os.Exit(2)
+	}
+
+	if err := targets.CheckLimit(*maxHosts); err != nil {
+		fmt.Println("Erro:", err)
+		os.Exit(2)
+	}
+	total := targets.Count()
+	if done := checkpoint.Progress(); done > 0 {
+		fmt.Printf("Retomando varredura de %d endereços (%d já concluídos)\n", total, done)
+	} else {
+		fmt.Printf("Varrendo %d endereços\n", total)
+	}
+
+	ports, err := ParsePorts(*discoveryPorts)
+	if err != nil {
+		fmt.Println("Erro:", err)
+		os.Exit(2)
+	}
+	prober, err := NewProber(*probe, ports)
+	if err != nil {
+		fmt.Println("Erro:", err)
+		os.Exit(2)
+	}
+
+	scanner := &Scanner{Prober: prober, Workers: *workers, Rate: *rate, Timeout: *timeout}
+	if *scanPorts != "" {
+		ports, err := ParsePorts(*scanPorts)
+		if err != nil {
+			fmt.Println("Erro:", err)
+			os.Exit(2)
+		}
+		scanner.Ports = &PortScanner{Ports: ports, Timeout: *portTimeout, Workers: *portWorkers, Banner: *banner}
+	}
+
+	// Ctrl+C interrompe a varredura e mostra o resumo parcial
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	summary := scanner.Scan(ctx, checkpoint.Filter(targets.All()), func(r Result) {
+		if err := checkpoint.Record(r); err != nil {
+			fmt.Println("Erro ao salvar o estado:", err)
+		}
+
+		switch {
+		case r.Err != nil:
+			fmt.Println(r.Addr, "- Erro:", r.Err)
+		case r.Up:
+			fmt.Println(r.Addr, "- Host ativo", r.RTT.Round(time.Millisecond))
+			for _, p := range r.Ports {
+				fmt.Println(strings.TrimRight(fmt.Sprintf("    %d/tcp aberta  %s", p.Port, p.Banner), " "))
 			}
+		case *verbose:
+			fmt.Println(r.Addr, "- Host inativo")
+		}
+	})
+
+	printSummary(summary, total)
+
+	if summary.Interrupted {
+		if err := checkpoint.Save(); err != nil {
+			fmt.Println("Erro ao salvar o estado:", err)
+		} else if *statePath != "" {
+			fmt.Printf("Estado salvo em %s; retome com -state %s -resume\n", *statePath, *statePath)
+		}
+	} else if err := checkpoint.Remove(); err != nil {
+		fmt.Println("Erro ao remover o estado:", err)
+	}
+
+	if *output != "" {
+		if err := WriteReportFile(*output, checkpoint.Report(!summary.Interrupted)); err != nil {
+			fmt.Println("Erro ao gravar o resultado:", err)
+			os.Exit(1)
 		}
+		fmt.Println("Resultado salvo em", *output)
+	}
+}
+
+// openCheckpoint começa uma varredura nova ou, com resume, carrega a salva
+// em path. Uma varredura nova não sobrescreve um estado salvo existente.
+func openCheckpoint(path string, resume bool, specs []string) (*Checkpoint, error) {
+	if !resume {
+		if path != "" {
+			if _, err := os.Stat(path); err == nil {
+				return nil, fmt.Errorf("já existe uma varredura salva em %s; use -resume ou apague o arquivo", path)
+			}
+		}
+		return NewCheckpoint(path, specs), nil
+	}
+
+	if path == "" {
+		return nil, fmt.Errorf("-resume precisa de -state")
+	}
+	checkpoint, err := LoadCheckpoint(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(specs) > 0 && !slices.Equal(specs, checkpoint.Targets()) {
+		return nil, fmt.Errorf("os alvos informados diferem dos da varredura salva em %s", path)
+	}
+	return checkpoint, nil
+}
+
+func printSummary(s Summary, total uint64) {
+	if s.Interrupted {
+		fmt.Printf("\nVarredura interrompida: %d de %d endereços sondados\n", s.Probed, total)
+	} else {
+		fmt.Printf("\nVarredura concluída: %d endereços sondados\n", s.Probed)
 	}
+	fmt.Printf("Ativos: %d  Inativos: %d  Erros: %d  Tempo: %s\n", s.Up, s.Down, s.Errors, s.Elapsed.Round(time.Millisecond))
 }
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// ParsePorts interpreta uma lista de portas e intervalos separados por
+// vírgula, por exemplo "22,80,8000-8100". Portas repetidas são ignoradas e a
+// ordem da lista é mantida.
+func ParsePorts(list string) ([]uint16, error) {
+	var ports []uint16
+	seen := make(map[uint16]bool)
+	for _, item := range strings.Split(list, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		first, last, isRange := strings.Cut(item, "-")
+		if !isRange {
+			last = first
+		}
+		lo, err := parsePort(first)
+		if err != nil {
+			return nil, err
+		}
+		hi, err := parsePort(last)
+		if err != nil {
+			return nil, err
+		}
+		if hi < lo {
+			return nil, fmt.Errorf("intervalo de portas inválido %q", item)
+		}
+
+		for p := int(lo); p <= int(hi); p++ {
+			if !seen[uint16(p)] {
+				seen[uint16(p)] = true
+				ports = append(ports, uint16(p))
+			}
+		}
+	}
+	return ports, nil
+}
+
+func parsePort(s string) (uint16, error) {
+	n, err := strconv.ParseUint(strings.TrimSpace(s), 10, 16)
+	if err != nil || n == 0 {
+		return 0, fmt.Errorf("porta inválida %q", s)
+	}
+	return uint16(n), nil
+}
+
+// PortResult é uma porta TCP encontrada aberta. Banner traz a identificação
+// do serviço quando a leitura de banners está ativa e o serviço se apresenta.
+type PortResult struct {
+	Port   uint16 `json:"port"`
+	Banner string `json:"banner,omitempty"`
+}
+
+// PortScanner verifica portas TCP de um host já conhecido como ativo.
+//
+// Timeout é o prazo de cada conexão e também da leitura do banner. Workers
+// limita as conexões simultâneas por host.
+type PortScanner struct {
+	Ports   []uint16
+	Timeout time.Duration
+	Workers int
+	Banner  bool
+}
+
+// ScanHost conecta em todas as portas e retorna as abertas, em ordem
+// crescente. wait é chamado antes de cada conexão para respeitar o limite de
+// taxa da varredura; se retornar erro, a porta não é verificada.
+func (s *PortScanner) ScanHost(ctx context.Context, addr netip.Addr, wait func(context.Context) error) []PortResult {
+	ports := make(chan uint16)
+	go func() {
+		defer close(ports)
+		for _, p := range s.Ports {
+			select {
+			case ports <- p:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var (
+		mu   sync.Mutex
+		open []PortResult
+		wg   sync.WaitGroup
+	)
+	for range max(s.Workers, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for port := range ports {
+				if wait(ctx) != nil {
+					continue
+				}
+				if r, ok := s.scanPort(ctx, addr, port); ok {
+					mu.Lock()
+					open = append(open, r)
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	sort.Slice(open, func(i, j int) bool { return open[i].Port < open[j].Port })
+	return open
+}
+
+func (s *PortScanner) scanPort(ctx context.Context, addr netip.Addr, port uint16) (PortResult, bool) {
+	dialer := net.Dialer{Timeout: s.Timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", netip.AddrPortFrom(addr, port).String())
+	if err != nil {
+		return PortResult{}, false
+	}
+	defer conn.Close()
+
+	r := PortResult{Port: port}
+	if s.Banner {
+		r.Banner = grabBanner(conn, s.Timeout)
+	}
+	return r, true
+}
+
+// maxBannerLen limita o tamanho do banner guardado no resultado.
+const maxBannerLen = 120
+
+// grabBanner lê o que o serviço envia ao conectar (SSH, SMTP, FTP...). Se nada
+// chegar no prazo, envia um HEAD de HTTP e usa o cabeçalho Server da resposta,
+// ou a linha de status quando não houver esse cabeçalho.
+func grabBanner(conn net.Conn, timeout time.Duration) string {
+	reader := bufio.NewReader(conn)
+
+	conn.SetReadDeadline(time.Now().Add(timeout))
+	if line, err := reader.ReadString('\n'); err == nil || line != "" {
+		return cleanBanner(line)
+	}
+
+	conn.SetDeadline(time.Now().Add(timeout))
+	if _, err := io.WriteString(conn, "HEAD / HTTP/1.0\r\n\r\n"); err != nil {
+		return ""
+	}
+	resp, err := http.ReadResponse(reader, nil)
+	if err != nil {
+		return ""
+	}
+	resp.Body.Close()
+	if server := resp.Header.Get("Server"); server != "" {
+		return cleanBanner("HTTP " + server)
+	}
+	return cleanBanner(resp.Proto + " " + resp.Status)
+}
+
+// cleanBanner mantém apenas caracteres imprimíveis da primeira linha.
+func cleanBanner(s string) string {
+	s, _, _ = strings.Cut(s, "\n")
+	s = strings.Map(func(r rune) rune {
+		if !unicode.IsPrint(r) {
+			return -1
+		}
+		return r
+	}, s)
+	s = strings.TrimSpace(s)
+	if len(s) > maxBannerLen {
+		s = s[:maxBannerLen]
+	}
+	return s
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// listen abre um servidor TCP em 127.0.0.1 que chama serve para cada conexão.
+func listen(t *testing.T, serve func(net.Conn)) uint16 {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				serve(conn)
+			}()
+		}
+	}()
+	return uint16(ln.Addr().(*net.TCPAddr).Port)
+}
+
+func noWait(context.Context) error { return nil }
+
+func TestScanHost(t *testing.T) {
+	silent := listen(t, func(conn net.Conn) { io.Copy(io.Discard, conn) })
+	ssh := listen(t, func(conn net.Conn) { io.WriteString(conn, "SSH-2.0-OpenSSH_9.6\r\n") })
+	closed := unusedPort(t)
+
+	s := &PortScanner{Ports: []uint16{ssh, closed, silent}, Timeout: time.Second, Workers: 2}
+	got := s.ScanHost(context.Background(), netip.MustParseAddr("127.0.0.1"), noWait)
+
+	want := []PortResult{{Port: silent}, {Port: ssh}}
+	slices.SortFunc(want, func(a, b PortResult) int { return int(a.Port) - int(b.Port) })
+	if !slices.Equal(got, want) {
+		t.Errorf("ScanHost = %+v, quer %+v", got, want)
+	}
+}
+
+func TestScanHostWait(t *testing.T) {
+	open := listen(t, func(net.Conn) {})
+	s := &PortScanner{Ports: []uint16{open}, Timeout: time.Second}
+
+	// Se wait falha (limite de taxa cancelado), a porta não é verificada
+	got := s.ScanHost(context.Background(), netip.MustParseAddr("127.0.0.1"), func(context.Context) error {
+		return context.Canceled
+	})
+	if len(got) != 0 {
+		t.Errorf("ScanHost = %+v, quer nenhuma porta", got)
+	}
+}
+
+func TestScanHostBanner(t *testing.T) {
+	ssh := listen(t, func(conn net.Conn) { io.WriteString(conn, "SSH-2.0-OpenSSH_9.6\r\n") })
+	smtp := listen(t, func(conn net.Conn) { io.WriteString(conn, "220 mail.exemplo \x1b[1mESMTP\x00 pronto") })
+	silent := listen(t, func(conn net.Conn) { io.Copy(io.Discard, conn) })
+
+	// Serviços HTTP não se apresentam: o banner vem da resposta a um HEAD
+	web := listen(t, func(conn net.Conn) {
+		req, err := http.ReadRequest(bufio.NewReader(conn))
+		if err != nil || req.Method != http.MethodHead {
+			return
+		}
+		io.WriteString(conn, "HTTP/1.0 200 OK\r\nServer: nginx/1.25\r\n\r\n")
+	})
+	bare := listen(t, func(conn net.Conn) {
+		if _, err := http.ReadRequest(bufio.NewReader(conn)); err == nil {
+			io.WriteString(conn, "HTTP/1.1 404 Not Found\r\n\r\n")
+		}
+	})
+
+	ports := []uint16{ssh, smtp, silent, web, bare}
+	s := &PortScanner{Ports: ports, Timeout: 200 * time.Millisecond, Workers: len(ports), Banner: true}
+	results := s.ScanHost(context.Background(), netip.MustParseAddr("127.0.0.1"), noWait)
+
+	banners := make(map[uint16]string)
+	for _, r := range results {
+		banners[r.Port] = r.Banner
+	}
+	want := map[uint16]string{
+		ssh:    "SSH-2.0-OpenSSH_9.6",
+		smtp:   "220 mail.exemplo [1mESMTP pronto",
+		silent: "",
+		web:    "HTTP nginx/1.25",
+		bare:   "HTTP/1.1 404 Not Found",
+	}
+	for port, banner := range want {
+		got, ok := banners[port]
+		if !ok {
+			t.Errorf("porta %d não encontrada", port)
+		} else if got != banner {
+			t.Errorf("banner da porta %d = %q, quer %q", port, got, banner)
+		}
+	}
+}
+
+func TestCleanBanner(t *testing.T) {
+	long := strings.Repeat("x", maxBannerLen+10)
+	tests := []struct{ in, want string }{
+		{"SSH-2.0-OpenSSH_9.6\r\n", "SSH-2.0-OpenSSH_9.6"},
+		{"  220 pronto\r\nsegunda linha\n", "220 pronto"},
+		{"a\x00b\tc\x7f", "abc"},
+		{long, long[:maxBannerLen]},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := cleanBanner(tt.in); got != tt.want {
+			t.Errorf("cleanBanner(%q) = %q, quer %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParsePorts(t *testing.T) {
+	tests := []struct {
+		list string
+		want []uint16
+	}{
+		{"22", []uint16{22}},
+		{"443, 80,22", []uint16{443, 80, 22}},
+		{"8000-8003,8001,22", []uint16{8000, 8001, 8002, 8003, 22}},
+		{"65535-65535", []uint16{65535}},
+		{",,", nil},
+	}
+	for _, tt := range tests {
+		got, err := ParsePorts(tt.list)
+		if err != nil || !slices.Equal(got, tt.want) {
+			t.Errorf("ParsePorts(%q) = %v, %v; quer %v", tt.list, got, err, tt.want)
+		}
+	}
+
+	for _, list := range []string{"0", "65536", "http", "90-80", "1-2-3", "-5"} {
+		if got, err := ParsePorts(list); err == nil {
+			t.Errorf("ParsePorts(%q) = %v, esperava erro", list, got)
+		}
+	}
+}
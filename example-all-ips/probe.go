@@ -0,0 +1,325 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/netip"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+)
+
+// Prober verifica se um host responde. Probe deve respeitar o cancelamento
+// e o prazo do contexto.
+type Prober interface {
+	Probe(ctx context.Context, addr netip.Addr) (bool, error)
+}
+
+// NewProber monta o prober a partir de uma lista de estratégias separadas por
+// vírgula (ping, icmp, tcp, arp). Com mais de uma, o host é considerado ativo
+// se qualquer estratégia o detectar.
+func NewProber(list string, ports []uint16) (Prober, error) {
+	var probers []Prober
+	for _, name := range strings.Split(list, ",") {
+		switch strings.TrimSpace(name) {
+		case "ping":
+			probers = append(probers, PingProber{})
+		case "icmp":
+			probers = append(probers, ICMPProber{})
+		case "icmp-raw":
+			probers = append(probers, ICMPProber{Privileged: true})
+		case "tcp":
+			if len(ports) == 0 {
+				return nil, errors.New("a estratégia tcp precisa de ao menos uma porta")
+			}
+			probers = append(probers, TCPProber{Ports: ports})
+		case "arp":
+			probers = append(probers, NewNeighborProber("/proc/net/arp"))
+		default:
+			return nil, fmt.Errorf("estratégia desconhecida %q (use ping, icmp, icmp-raw, tcp ou arp)", name)
+		}
+	}
+	if len(probers) == 1 {
+		return probers[0], nil
+	}
+	return AnyProber(probers), nil
+}
+
+// PingProber usa o comando ping do sistema operacional.
+type PingProber struct{}
+
+// Probe envia um único echo com o ping do sistema. Um código de saída
+// diferente de zero significa host inativo; outros erros (por exemplo, o
+// ping não instalado) são retornados.
+func (PingProber) Probe(ctx context.Context, addr netip.Addr) (bool, error) {
+	ip := addr.String()
+
+	// Comando ping diferente para Windows e Linux/macOS
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "ping", "-n", "1", ip)
+	} else {
+		cmd = exec.CommandContext(ctx, "ping", "-c", "1", ip)
+	}
+
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// ICMPProber envia um echo ICMP nativo, sem depender do binário ping.
+//
+// Por padrão usa sockets de datagrama ICMP, que no Linux não exigem root
+// quando o grupo do usuário está em net.ipv4.ping_group_range (no macOS
+// funcionam sem configuração). Com Privileged, usa sockets raw, que exigem
+// root ou CAP_NET_RAW.
+type ICMPProber struct {
+	Privileged bool
+}
+
+// icmpProbeTimeout é o prazo usado quando o contexto não define um.
+const icmpProbeTimeout = 2 * time.Second
+
+// Probe envia um echo request e espera o echo reply correspondente até o
+// prazo do contexto. Sem resposta no prazo, o host é considerado inativo.
+func (p ICMPProber) Probe(ctx context.Context, addr netip.Addr) (bool, error) {
+	network, listen, proto := "udp4", "0.0.0.0", 1
+	var echo, reply icmp.Type = ipv4.ICMPTypeEcho, ipv4.ICMPTypeEchoReply
+	if addr.Is6() {
+		network, listen, proto = "udp6", "::", 58
+		echo, reply = ipv6.ICMPTypeEchoRequest, ipv6.ICMPTypeEchoReply
+	}
+	if p.Privileged {
+		network = "ip4:icmp"
+		if addr.Is6() {
+			network = "ip6:ipv6-icmp"
+		}
+	}
+
+	conn, err := icmp.ListenPacket(network, listen)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(icmpProbeTimeout)
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		return false, err
+	}
+
+	// Em sockets de datagrama o kernel troca o ID pelo da porta local, por
+	// isso a resposta é reconhecida pela sequência e pelo remetente
+	seq := rand.IntN(1 << 16)
+	msg := icmp.Message{Type: echo, Body: &icmp.Echo{ID: os.Getpid() & 0xffff, Seq: seq, Data: []byte("all-ips")}}
+	b, err := msg.Marshal(nil)
+	if err != nil {
+		return false, err
+	}
+
+	var dst net.Addr = &net.UDPAddr{IP: addr.AsSlice(), Zone: addr.Zone()}
+	if p.Privileged {
+		dst = &net.IPAddr{IP: addr.AsSlice(), Zone: addr.Zone()}
+	}
+	if _, err := conn.WriteTo(b, dst); err != nil {
+		return false, err
+	}
+
+	buf := make([]byte, 1500)
+	for {
+		n, peer, err := conn.ReadFrom(buf)
+		if err != nil {
+			var netErr net.Error
+			if (errors.As(err, &netErr) && netErr.Timeout()) || ctx.Err() != nil {
+				return false, nil
+			}
+			return false, err
+		}
+
+		if !sameHost(peer, addr) {
+			continue
+		}
+		rm, err := icmp.ParseMessage(proto, buf[:n])
+		if err != nil || rm.Type != reply {
+			continue
+		}
+		if body, ok := rm.Body.(*icmp.Echo); ok && body.Seq == seq {
+			return true, nil
+		}
+	}
+}
+
+func sameHost(peer net.Addr, addr netip.Addr) bool {
+	var ip net.IP
+	switch a := peer.(type) {
+	case *net.UDPAddr:
+		ip = a.IP
+	case *net.IPAddr:
+		ip = a.IP
+	default:
+		return false
+	}
+	got, ok := netip.AddrFromSlice(ip)
+	return ok && got.Unmap() == addr.WithZone("")
+}
+
+// TCPProber tenta conectar em uma lista de portas. O host é considerado
+// ativo se alguma conexão for aceita ou recusada: uma recusa (RST) também
+// prova que há alguém respondendo no endereço.
+type TCPProber struct {
+	Ports []uint16
+}
+
+// Packets informa ao Scanner quantos pacotes cada sondagem envia.
+func (p TCPProber) Packets() int {
+	return len(p.Ports)
+}
+
+// Probe conecta em todas as portas em paralelo e para na primeira resposta.
+func (p TCPProber) Probe(ctx context.Context, addr netip.Addr) (bool, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	found := make(chan bool, len(p.Ports))
+	var dialer net.Dialer
+	for _, port := range p.Ports {
+		go func(port uint16) {
+			conn, err := dialer.DialContext(ctx, "tcp", netip.AddrPortFrom(addr, port).String())
+			if err == nil {
+				conn.Close()
+			}
+			found <- err == nil || errors.Is(err, syscall.ECONNREFUSED)
+		}(port)
+	}
+
+	for range p.Ports {
+		if <-found {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// NeighborProber consulta a tabela de vizinhos (ARP) do kernel Linux em
+// /proc/net/arp. Não envia pacotes: detecta hosts com quem a máquina falou
+// recentemente, inclusive os que descartam ICMP e TCP. Combinado com outra
+// estratégia (por exemplo "tcp,arp"), a tentativa de conexão faz o kernel
+// resolver o endereço e o host aparece na tabela. Só há suporte a IPv4.
+type NeighborProber struct {
+	Path string
+	TTL  time.Duration
+
+	mu     sync.Mutex
+	table  map[netip.Addr]bool
+	loaded time.Time
+}
+
+// NewNeighborProber cria um prober que relê a tabela no máximo uma vez por segundo.
+func NewNeighborProber(path string) *NeighborProber {
+	return &NeighborProber{Path: path, TTL: time.Second}
+}
+
+// Packets retorna zero: a consulta é local e não conta no limite de taxa.
+func (p *NeighborProber) Packets() int {
+	return 0
+}
+
+// Probe informa se o endereço tem uma entrada completa na tabela de vizinhos.
+func (p *NeighborProber) Probe(ctx context.Context, addr netip.Addr) (bool, error) {
+	if !addr.Is4() {
+		return false, errors.New("tabela de vizinhos disponível apenas para IPv4")
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.table == nil || time.Since(p.loaded) > p.TTL {
+		f, err := os.Open(p.Path)
+		if err != nil {
+			return false, err
+		}
+		table, err := readNeighbors(f)
+		f.Close()
+		if err != nil {
+			return false, err
+		}
+		p.table, p.loaded = table, time.Now()
+	}
+	return p.table[addr], nil
+}
+
+// readNeighbors lê a tabela no formato de /proc/net/arp. Entradas sem a
+// flag ATF_COM (0x2) ainda estão sendo resolvidas ou falharam, e não contam
+// como host ativo; linhas que não seguem o formato são ignoradas.
+func readNeighbors(r io.Reader) (map[netip.Addr]bool, error) {
+	table := make(map[netip.Addr]bool)
+	scanner := bufio.NewScanner(r)
+	scanner.Scan() // cabeçalho
+	for scanner.Scan() {
+		// IP address, HW type, Flags, HW address, Mask, Device
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 {
+			continue
+		}
+		addr, err := netip.ParseAddr(fields[0])
+		if err != nil {
+			continue
+		}
+		var flags int
+		fmt.Sscanf(fields[2], "0x%x", &flags)
+		if flags&0x2 != 0 && fields[3] != "00:00:00:00:00:00" {
+			table[addr] = true
+		}
+	}
+	return table, scanner.Err()
+}
+
+// AnyProber aplica as estratégias em ordem e considera o host ativo na
+// primeira que o detectar. Erros só são retornados se nenhuma detectar.
+type AnyProber []Prober
+
+// Packets soma os pacotes de todas as estratégias.
+func (p AnyProber) Packets() int {
+	total := 0
+	for _, prober := range p {
+		if pc, ok := prober.(packetCounter); ok {
+			total += pc.Packets()
+		} else {
+			total++
+		}
+	}
+	return total
+}
+
+func (p AnyProber) Probe(ctx context.Context, addr netip.Addr) (bool, error) {
+	var errs []error
+	for _, prober := range p {
+		up, err := prober.Probe(ctx, addr)
+		if up {
+			return true, nil
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return false, errors.Join(errs...)
+}
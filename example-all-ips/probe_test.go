@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"maps"
+	"net/netip"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestReadNeighbors(t *testing.T) {
+	f, err := os.Open("testdata/arp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	table, err := readNeighbors(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Incompletas (0x0), sem endereço de hardware e linhas malformadas ficam
+	// de fora
+	got := slices.SortedFunc(maps.Keys(table), netip.Addr.Compare)
+	want := []netip.Addr{
+		netip.MustParseAddr("10.8.0.5"),
+		netip.MustParseAddr("192.168.0.1"),
+		netip.MustParseAddr("192.168.0.20"),
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("readNeighbors = %v, quer %v", got, want)
+	}
+
+	// Só o cabeçalho, ou nada
+	for _, data := range []string{"IP address HW type Flags HW address Mask Device\n", ""} {
+		table, err := readNeighbors(strings.NewReader(data))
+		if err != nil || len(table) != 0 {
+			t.Errorf("readNeighbors(%q) = %v, %v", data, table, err)
+		}
+	}
+}
+
+func TestNeighborProber(t *testing.T) {
+	p := NewNeighborProber("testdata/arp")
+	tests := []struct {
+		addr string
+		up   bool
+	}{
+		{"192.168.0.1", true},
+		{"192.168.0.31", false},
+		{"192.168.0.99", false},
+	}
+	for _, tt := range tests {
+		up, err := p.Probe(context.Background(), netip.MustParseAddr(tt.addr))
+		if err != nil || up != tt.up {
+			t.Errorf("Probe(%s) = %v, %v; quer %v", tt.addr, up, err, tt.up)
+		}
+	}
+	if _, err := p.Probe(context.Background(), netip.MustParseAddr("2001:db8::1")); err == nil {
+		t.Error("Probe aceitou IPv6")
+	}
+
+	missing := NewNeighborProber("testdata/nao-existe")
+	if _, err := missing.Probe(context.Background(), netip.MustParseAddr("192.168.0.1")); err == nil {
+		t.Error("Probe sem a tabela não retornou erro")
+	}
+}
+
+func TestNewProber(t *testing.T) {
+	ports := []uint16{22, 80}
+	arp := NewNeighborProber("/proc/net/arp")
+	tests := []struct {
+		list string
+		want Prober
+	}{
+		{"ping", PingProber{}},
+		{"icmp", ICMPProber{}},
+		{"icmp-raw", ICMPProber{Privileged: true}},
+		{"tcp", TCPProber{Ports: ports}},
+		{"arp", arp},
+		// Mais de uma: em ordem, dentro de um AnyProber
+		{"tcp, arp", AnyProber{TCPProber{Ports: ports}, arp}},
+		{"icmp,ping", AnyProber{ICMPProber{}, PingProber{}}},
+	}
+	for _, tt := range tests {
+		got, err := NewProber(tt.list, ports)
+		if err != nil {
+			t.Errorf("NewProber(%q): %v", tt.list, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("NewProber(%q) = %#v, quer %#v", tt.list, got, tt.want)
+		}
+	}
+
+	for _, list := range []string{"", "syn", "ping,", "tcp"} {
+		if _, err := NewProber(list, nil); err == nil {
+			t.Errorf("NewProber(%q) aceitou", list)
+		}
+	}
+}
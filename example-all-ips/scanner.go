@@ -0,0 +1,185 @@
+package main
+
+import (
+	"context"
+	"iter"
+	"net/netip"
+	"sync"
+	"time"
+)
+
+// Result é o resultado da sondagem de um endereço. Ports só é preenchido
+// para hosts ativos quando a varredura de portas está ligada.
+type Result struct {
+	Addr  netip.Addr
+	Up    bool
+	RTT   time.Duration
+	Err   error
+	Ports []PortResult
+}
+
+// Summary resume uma varredura. Interrupted indica que o contexto foi
+// cancelado antes de todos os endereços serem sondados.
+type Summary struct {
+	Probed      int
+	Up          int
+	Down        int
+	Errors      int
+	Elapsed     time.Duration
+	Interrupted bool
+}
+
+// Scanner sonda endereços em paralelo.
+//
+// Workers limita as sondagens simultâneas, Rate limita os pacotes enviados
+// por segundo em toda a varredura (0 desativa) e Timeout é o prazo de cada
+// sondagem. Com Ports definido, as portas de cada host ativo são verificadas
+// logo após a detecção, dentro do mesmo limite de taxa.
+type Scanner struct {
+	Prober  Prober
+	Workers int
+	Rate    float64
+	Timeout time.Duration
+	Ports   *PortScanner
+}
+
+// packetCounter é implementado por probers que não enviam exatamente um
+// pacote por sondagem, para que o limite de taxa conte todos eles.
+type packetCounter interface {
+	Packets() int
+}
+
+// Scan sonda todos os endereços e chama report para cada resultado, sempre
+// na mesma goroutine. Quando o contexto é cancelado, as sondagens em
+// andamento são descartadas e o resumo parcial é retornado.
+func (s *Scanner) Scan(ctx context.Context, addrs iter.Seq[netip.Addr], report func(Result)) Summary {
+	start := time.Now()
+
+	packets := 1
+	if pc, ok := s.Prober.(packetCounter); ok {
+		packets = pc.Packets()
+	}
+	limit := newLimiter(s.Rate)
+	defer limit.stop()
+
+	jobs := make(chan netip.Addr)
+	results := make(chan Result)
+
+	go func() {
+		defer close(jobs)
+		for addr := range addrs {
+			if err := limit.wait(ctx, packets); err != nil {
+				return
+			}
+			select {
+			case jobs <- addr:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for range max(s.Workers, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for addr := range jobs {
+				r, ok := s.probe(ctx, addr)
+				if !ok {
+					continue
+				}
+				if r.Up && s.Ports != nil {
+					r.Ports = s.Ports.ScanHost(ctx, addr, func(ctx context.Context) error { return limit.wait(ctx, 1) })
+					if ctx.Err() != nil {
+						continue
+					}
+				}
+				results <- r
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	var summary Summary
+	for r := range results {
+		summary.Probed++
+		switch {
+		case r.Err != nil:
+			summary.Errors++
+		case r.Up:
+			summary.Up++
+		default:
+			summary.Down++
+		}
+		report(r)
+	}
+	summary.Elapsed = time.Since(start)
+	summary.Interrupted = ctx.Err() != nil
+	return summary
+}
+
+// probe sonda um endereço com o prazo da varredura. Retorna false quando a
+// varredura foi cancelada durante a sondagem, cujo resultado não vale.
+func (s *Scanner) probe(ctx context.Context, addr netip.Addr) (Result, bool) {
+	probeCtx := ctx
+	if s.Timeout > 0 {
+		var cancel context.CancelFunc
+		probeCtx, cancel = context.WithTimeout(ctx, s.Timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	up, err := s.Prober.Probe(probeCtx, addr)
+	if ctx.Err() != nil {
+		return Result{}, false
+	}
+
+	r := Result{Addr: addr, Up: up, Err: err}
+	if up {
+		r.RTT = time.Since(start)
+	}
+	return r, true
+}
+
+// MaxRate é o maior limite de taxa aceito, em pacotes por segundo. Acima
+// disso o intervalo entre pacotes ficaria perto de zero (e, passando de 1e9,
+// igual a zero, o que time.NewTicker não aceita).
+const MaxRate = 1e6
+
+// limiter libera no máximo rate pacotes por segundo. Um limiter com rate
+// zero não limita; rates acima de MaxRate valem como MaxRate.
+type limiter struct {
+	ticker *time.Ticker
+}
+
+func newLimiter(rate float64) *limiter {
+	if !(rate > 0) {
+		return &limiter{}
+	}
+	rate = min(rate, MaxRate)
+	return &limiter{ticker: time.NewTicker(time.Duration(float64(time.Second) / rate))}
+}
+
+func (l *limiter) wait(ctx context.Context, n int) error {
+	if l.ticker == nil {
+		return ctx.Err()
+	}
+	for range n {
+		select {
+		case <-l.ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+func (l *limiter) stop() {
+	if l.ticker != nil {
+		l.ticker.Stop()
+	}
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net"
+	"net/netip"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeProber responde a partir de um mapa fixo e conta as sondagens.
+type fakeProber struct {
+	up    map[netip.Addr]bool
+	errs  map[netip.Addr]error
+	delay time.Duration
+
+	mu     sync.Mutex
+	probed []netip.Addr
+}
+
+func (p *fakeProber) Probe(ctx context.Context, addr netip.Addr) (bool, error) {
+	p.mu.Lock()
+	p.probed = append(p.probed, addr)
+	p.mu.Unlock()
+	if p.delay > 0 {
+		select {
+		case <-time.After(p.delay):
+		case <-ctx.Done():
+			return false, ctx.Err()
+		}
+	}
+	return p.up[addr], p.errs[addr]
+}
+
+func mustTargets(t *testing.T, specs ...string) Targets {
+	t.Helper()
+	targets, err := ParseTargets(specs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return targets
+}
+
+func TestScan(t *testing.T) {
+	a := netip.MustParseAddr
+	prober := &fakeProber{
+		up:   map[netip.Addr]bool{a("10.0.0.2"): true, a("10.0.0.5"): true},
+		errs: map[netip.Addr]error{a("10.0.0.3"): errors.New("falhou")},
+	}
+	scanner := &Scanner{Prober: prober, Workers: 4}
+
+	var up []netip.Addr
+	summary := scanner.Scan(context.Background(), mustTargets(t, "10.0.0.1-10.0.0.6").All(), func(r Result) {
+		if r.Up {
+			up = append(up, r.Addr)
+		}
+	})
+
+	slices.SortFunc(up, netip.Addr.Compare)
+	if want := []netip.Addr{a("10.0.0.2"), a("10.0.0.5")}; !slices.Equal(up, want) {
+		t.Errorf("ativos = %v, quer %v", up, want)
+	}
+	if summary.Probed != 6 || summary.Up != 2 || summary.Errors != 1 || summary.Down != 3 || summary.Interrupted {
+		t.Errorf("resumo = %+v", summary)
+	}
+}
+
+func TestScanRate(t *testing.T) {
+	prober := &fakeProber{}
+	scanner := &Scanner{Prober: prober, Workers: 8, Rate: 100}
+
+	start := time.Now()
+	summary := scanner.Scan(context.Background(), mustTargets(t, "10.0.0.1-10.0.0.10").All(), func(Result) {})
+	// 10 pacotes a 100 por segundo: o primeiro sai após 10ms, o último após 100ms
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("10 sondagens a 100/s levaram só %v", elapsed)
+	}
+	if summary.Probed != 10 {
+		t.Errorf("resumo = %+v", summary)
+	}
+}
+
+func TestScanInterrupted(t *testing.T) {
+	prober := &fakeProber{delay: time.Hour}
+	scanner := &Scanner{Prober: prober, Workers: 2}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	summary := scanner.Scan(ctx, mustTargets(t, "10.0.0.0/24").All(), func(r Result) {
+		t.Errorf("resultado de sondagem cancelada: %+v", r)
+	})
+	if !summary.Interrupted || summary.Probed != 0 {
+		t.Errorf("resumo = %+v", summary)
+	}
+}
+
+func TestNewLimiter(t *testing.T) {
+	// Acima de 1e9 o intervalo seria zero e time.NewTicker entraria em pânico
+	for _, rate := range []float64{0, -1, math.NaN(), 10, 1e9, 2e9, math.Inf(1)} {
+		l := newLimiter(rate)
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		if err := l.wait(ctx, 2); err != nil {
+			t.Errorf("rate %v: %v", rate, err)
+		}
+		cancel()
+		l.stop()
+	}
+}
+
+func TestTCPProberLoopback(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+	open := uint16(ln.Addr().(*net.TCPAddr).Port)
+	closed := unusedPort(t)
+	loopback := netip.MustParseAddr("127.0.0.1")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for _, ports := range [][]uint16{{open}, {closed}, {closed, open}} {
+		// Porta fechada também prova que o host responde (RST)
+		if up, err := (TCPProber{Ports: ports}).Probe(ctx, loopback); !up || err != nil {
+			t.Errorf("portas %v: Probe() = %v, %v", ports, up, err)
+		}
+	}
+
+	scanner := &Scanner{Prober: TCPProber{Ports: []uint16{closed}}, Workers: 1, Timeout: time.Second}
+	var results []Result
+	scanner.Scan(ctx, mustTargets(t, "127.0.0.1").All(), func(r Result) { results = append(results, r) })
+	if len(results) != 1 || !results[0].Up || results[0].Addr != loopback {
+		t.Errorf("resultados = %+v", results)
+	}
+}
+
+// unusedPort retorna uma porta de 127.0.0.1 em que nada escuta.
+func unusedPort(t *testing.T) uint16 {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := uint16(ln.Addr().(*net.TCPAddr).Port)
+	ln.Close()
+	return port
+}
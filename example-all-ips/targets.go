@@ -0,0 +1,194 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"iter"
+	"math"
+	"net/netip"
+	"sort"
+	"strings"
+)
+
+// Range é um intervalo contínuo e inclusivo de endereços da mesma família.
+type Range struct {
+	First netip.Addr
+	Last  netip.Addr
+}
+
+// Count retorna a quantidade de endereços do intervalo, saturando em math.MaxUint64.
+func (r Range) Count() uint64 {
+	first, last := r.First.As16(), r.Last.As16()
+	hiFirst, loFirst := be64(first[:8]), be64(first[8:])
+	hiLast, loLast := be64(last[:8]), be64(last[8:])
+
+	hi := hiLast - hiFirst
+	lo := loLast - loFirst
+	if loLast < loFirst {
+		hi--
+	}
+	if hi > 0 || lo == math.MaxUint64 {
+		return math.MaxUint64
+	}
+	return lo + 1
+}
+
+func be64(b []byte) uint64 {
+	var v uint64
+	for _, x := range b {
+		v = v<<8 | uint64(x)
+	}
+	return v
+}
+
+// ParseTarget interpreta um alvo: um endereço (IPv4 ou IPv6), um prefixo CIDR
+// ou um intervalo "inicio-fim". Em prefixos IPv4 até /30 o endereço de rede e
+// o de broadcast são descartados; em prefixos IPv6 até /126 o endereço de
+// rede (anycast do roteador da sub-rede) é descartado.
+func ParseTarget(spec string) (Range, error) {
+	spec = strings.TrimSpace(spec)
+
+	if strings.Contains(spec, "/") {
+		prefix, err := netip.ParsePrefix(spec)
+		if err != nil {
+			return Range{}, err
+		}
+		return prefixRange(prefix.Masked()), nil
+	}
+
+	if first, last, ok := strings.Cut(spec, "-"); ok {
+		a, err := netip.ParseAddr(strings.TrimSpace(first))
+		if err != nil {
+			return Range{}, err
+		}
+		b, err := netip.ParseAddr(strings.TrimSpace(last))
+		if err != nil {
+			return Range{}, err
+		}
+		a, b = a.Unmap(), b.Unmap()
+		if a.Is4() != b.Is4() || b.Less(a) {
+			return Range{}, fmt.Errorf("intervalo inválido %q", spec)
+		}
+		return Range{First: a, Last: b}, nil
+	}
+
+	addr, err := netip.ParseAddr(spec)
+	if err != nil {
+		return Range{}, err
+	}
+	addr = addr.Unmap()
+	return Range{First: addr, Last: addr}, nil
+}
+
+func prefixRange(prefix netip.Prefix) Range {
+	first := prefix.Addr()
+	last := lastAddr(prefix)
+
+	hostBits := first.BitLen() - prefix.Bits()
+	if first.Is4() && hostBits >= 2 {
+		first, last = first.Next(), last.Prev()
+	} else if first.Is6() && hostBits >= 2 {
+		first = first.Next()
+	}
+	return Range{First: first, Last: last}
+}
+
+// lastAddr retorna o último endereço do prefixo (todos os bits de host em 1).
+func lastAddr(prefix netip.Prefix) netip.Addr {
+	b := prefix.Addr().AsSlice()
+	for i := prefix.Bits(); i < len(b)*8; i++ {
+		b[i/8] |= 1 << (7 - i%8)
+	}
+	addr, _ := netip.AddrFromSlice(b)
+	return addr
+}
+
+// Targets é o conjunto de endereços a varrer: intervalos ordenados, sem
+// sobreposição, IPv4 antes de IPv6.
+type Targets struct {
+	Ranges []Range
+}
+
+// ParseTargets interpreta uma lista de alvos (veja ParseTarget) e junta
+// intervalos sobrepostos ou adjacentes, de modo que cada endereço apareça
+// uma única vez e na ordem crescente.
+func ParseTargets(specs []string) (Targets, error) {
+	var ranges []Range
+	for _, spec := range specs {
+		r, err := ParseTarget(spec)
+		if err != nil {
+			return Targets{}, fmt.Errorf("alvo %q: %w", spec, err)
+		}
+		ranges = append(ranges, r)
+	}
+
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i].First.Less(ranges[j].First) })
+
+	var merged []Range
+	for _, r := range ranges {
+		if n := len(merged); n > 0 {
+			prev := &merged[n-1]
+			next := prev.Last.Next()
+			if prev.First.Is4() == r.First.Is4() && (!next.IsValid() || !next.Less(r.First)) {
+				if prev.Last.Less(r.Last) {
+					prev.Last = r.Last
+				}
+				continue
+			}
+		}
+		merged = append(merged, r)
+	}
+	return Targets{Ranges: merged}, nil
+}
+
+// ReadTargets lê alvos de um arquivo, um por linha. Linhas vazias e o texto
+// após '#' são ignorados.
+func ReadTargets(r io.Reader) ([]string, error) {
+	var specs []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		if line = strings.TrimSpace(line); line != "" {
+			specs = append(specs, line)
+		}
+	}
+	return specs, scanner.Err()
+}
+
+// Count retorna o total de endereços, saturando em math.MaxUint64.
+func (t Targets) Count() uint64 {
+	var total uint64
+	for _, r := range t.Ranges {
+		n := r.Count()
+		if total > math.MaxUint64-n {
+			return math.MaxUint64
+		}
+		total += n
+	}
+	return total
+}
+
+// CheckLimit retorna erro se houver mais que max endereços.
+func (t Targets) CheckLimit(max uint64) error {
+	if total := t.Count(); total > max {
+		return fmt.Errorf("%d endereços excedem o limite de %d (ajuste -max)", total, max)
+	}
+	return nil
+}
+
+// All percorre todos os endereços em ordem crescente.
+func (t Targets) All() iter.Seq[netip.Addr] {
+	return func(yield func(netip.Addr) bool) {
+		for _, r := range t.Ranges {
+			for addr := r.First; ; addr = addr.Next() {
+				if !yield(addr) {
+					return
+				}
+				if addr == r.Last {
+					break
+				}
+			}
+		}
+	}
+}
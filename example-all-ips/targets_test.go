@@ -0,0 +1,145 @@
+package main
+
+import (
+	"math"
+	"net/netip"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseTarget(t *testing.T) {
+	tests := []struct {
+		spec        string
+		first, last string
+		count       uint64
+	}{
+		{"192.168.0.10", "192.168.0.10", "192.168.0.10", 1},
+		{" 10.0.0.1 ", "10.0.0.1", "10.0.0.1", 1},
+		{"::ffff:10.0.0.1", "10.0.0.1", "10.0.0.1", 1},
+		{"2001:db8::1", "2001:db8::1", "2001:db8::1", 1},
+
+		// CIDR: rede e broadcast ficam de fora a partir de /30
+		{"192.168.0.0/24", "192.168.0.1", "192.168.0.254", 254},
+		{"192.168.0.77/24", "192.168.0.1", "192.168.0.254", 254},
+		{"10.0.0.0/30", "10.0.0.1", "10.0.0.2", 2},
+		{"10.0.0.0/31", "10.0.0.0", "10.0.0.1", 2},
+		{"10.0.0.5/32", "10.0.0.5", "10.0.0.5", 1},
+		{"2001:db8::/126", "2001:db8::1", "2001:db8::3", 3},
+		{"2001:db8::/127", "2001:db8::", "2001:db8::1", 2},
+		{"2001:db8::/64", "2001:db8::1", "2001:db8::ffff:ffff:ffff:ffff", math.MaxUint64},
+		{"::/0", "::1", "ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff", math.MaxUint64},
+
+		// Intervalos inclusivos
+		{"10.0.0.250-10.0.1.5", "10.0.0.250", "10.0.1.5", 12},
+		{"10.0.0.7 - 10.0.0.7", "10.0.0.7", "10.0.0.7", 1},
+		{"2001:db8::1-2001:db8::ff", "2001:db8::1", "2001:db8::ff", 255},
+		{"0.0.0.0-255.255.255.255", "0.0.0.0", "255.255.255.255", 1 << 32},
+	}
+	for _, tt := range tests {
+		r, err := ParseTarget(tt.spec)
+		if err != nil {
+			t.Errorf("ParseTarget(%q): %v", tt.spec, err)
+			continue
+		}
+		if r.First.String() != tt.first || r.Last.String() != tt.last {
+			t.Errorf("ParseTarget(%q) = %s-%s, quer %s-%s", tt.spec, r.First, r.Last, tt.first, tt.last)
+		}
+		if n := r.Count(); n != tt.count {
+			t.Errorf("ParseTarget(%q).Count() = %d, quer %d", tt.spec, n, tt.count)
+		}
+	}
+}
+
+func TestParseTargetInvalid(t *testing.T) {
+	tests := []string{
+		"",
+		"host.exemplo",
+		"256.0.0.1",
+		"10.0.0.0/33",
+		"10.0.0.0/",
+		"2001:db8::/129",
+		"10.0.0.9-10.0.0.1",
+		"10.0.0.1-2001:db8::1",
+		"10.0.0.1-",
+		"-10.0.0.1",
+		"10.0.0.1-10.0.0.2-10.0.0.3",
+	}
+	for _, spec := range tests {
+		if r, err := ParseTarget(spec); err == nil {
+			t.Errorf("ParseTarget(%q) = %+v, esperava erro", spec, r)
+		}
+	}
+}
+
+func TestParseTargetsMerge(t *testing.T) {
+	targets, err := ParseTargets([]string{
+		"2001:db8::5",
+		"10.0.0.10-10.0.0.20",
+		"10.0.0.15-10.0.0.30", // sobreposto
+		"10.0.0.31",           // adjacente
+		"10.0.0.40",
+		"2001:db8::4",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, r := range targets.Ranges {
+		got = append(got, r.First.String()+"-"+r.Last.String())
+	}
+	want := []string{"10.0.0.10-10.0.0.31", "10.0.0.40-10.0.0.40", "2001:db8::4-2001:db8::5"}
+	if !slices.Equal(got, want) {
+		t.Errorf("intervalos = %v, quer %v", got, want)
+	}
+	if n := targets.Count(); n != 22+1+2 {
+		t.Errorf("Count() = %d, quer 25", n)
+	}
+
+	var all []netip.Addr
+	for addr := range targets.All() {
+		all = append(all, addr)
+	}
+	if len(all) != 25 || all[0].String() != "10.0.0.10" || all[24].String() != "2001:db8::5" {
+		t.Errorf("All() = %v", all)
+	}
+
+	if _, err := ParseTargets([]string{"10.0.0.1", "nada"}); err == nil || !strings.Contains(err.Error(), `"nada"`) {
+		t.Errorf("erro = %v, quer mencionar o alvo inválido", err)
+	}
+}
+
+func TestTargetsCheckLimit(t *testing.T) {
+	tests := []struct {
+		specs []string
+		max   uint64
+		ok    bool
+	}{
+		{[]string{"10.0.0.0/24"}, 254, true},
+		{[]string{"10.0.0.0/24"}, 253, false},
+		{[]string{"10.0.0.0/24", "10.0.1.0/24"}, 508, true},
+		{[]string{"10.0.0.0/24", "10.0.1.0/24"}, 300, false},
+		{[]string{"10.0.0.0/8"}, 65536, false},
+		{[]string{"2001:db8::/64"}, math.MaxUint64 - 1, false},
+		{[]string{"::/0", "0.0.0.0/0"}, math.MaxUint64, true}, // a contagem satura
+	}
+	for _, tt := range tests {
+		targets, err := ParseTargets(tt.specs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := targets.CheckLimit(tt.max); (err == nil) != tt.ok {
+			t.Errorf("CheckLimit(%v, %d) = %v", tt.specs, tt.max, err)
+		}
+	}
+}
+
+func TestReadTargets(t *testing.T) {
+	specs, err := ReadTargets(strings.NewReader("# rede de casa\n192.168.0.0/24\n\n  10.0.0.1 # roteador\n#fim\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"192.168.0.0/24", "10.0.0.1"}; !slices.Equal(specs, want) {
+		t.Errorf("ReadTargets = %q, quer %q", specs, want)
+	}
+}
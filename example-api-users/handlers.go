@@ -0,0 +1,275 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// Server expõe o recurso /usuarios sobre um UserRepository. Todos os erros
+// são respondidos como application/problem+json (veja Problem).
+type Server struct {
+	Repo      UserRepository
+	Validator *Validator
+
+	ready atomic.Bool
+}
+
+// SetReady indica se o servidor deve receber tráfego; veja handleReady.
+func (s *Server) SetReady(ready bool) {
+	s.ready.Store(ready)
+}
+
+// Handler retorna o roteador da API.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /usuarios", s.handleList)
+	mux.HandleFunc("POST /usuarios", s.handleCreate)
+	mux.HandleFunc("GET /usuarios/{id}", s.handleGet)
+	mux.HandleFunc("PUT /usuarios/{id}", s.handleReplace)
+	mux.HandleFunc("PATCH /usuarios/{id}", s.handlePatch)
+	mux.HandleFunc("DELETE /usuarios/{id}", s.handleDelete)
+	mux.HandleFunc("GET /healthz", s.handleHealth)
+	mux.HandleFunc("GET /readyz", s.handleReady)
+	mux.Handle("/", notFound(mux))
+	return mux
+}
+
+// routeMethods são os métodos conferidos por notFound para montar o Allow.
+var routeMethods = []string{
+	http.MethodGet, http.MethodHead, http.MethodPost,
+	http.MethodPut, http.MethodPatch, http.MethodDelete,
+}
+
+// notFound atende o que nenhuma outra rota de mux atende, no lugar das
+// respostas em text/plain do próprio ServeMux: 405 com o cabeçalho Allow se
+// o caminho existe com outro método, senão 404.
+func notFound(mux *http.ServeMux) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var allow []string
+		for _, method := range routeMethods {
+			probe := r.Clone(r.Context())
+			probe.Method = method
+			if _, pattern := mux.Handler(probe); pattern != "/" {
+				allow = append(allow, method)
+			}
+		}
+		if len(allow) == 0 {
+			writeProblem(w, r, newProblem(http.StatusNotFound, "caminho não encontrado"))
+			return
+		}
+		w.Header().Set("Allow", strings.Join(allow, ", "))
+		writeProblem(w, r, newProblem(http.StatusMethodNotAllowed,
+			"use "+strings.Join(allow, ", ")+" em "+r.URL.Path))
+	})
+}
+
+// handleHealth responde 200 enquanto o processo estiver de pé.
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// handleReady responde 200 se o servidor já terminou de iniciar, ainda não
+// começou a encerrar e consegue consultar o repositório; senão, 503.
+func (s *Server) handleReady(w http.ResponseWriter, r *http.Request) {
+	if !s.ready.Load() {
+		writeProblem(w, r, newProblem(http.StatusServiceUnavailable, "servidor iniciando ou encerrando"))
+		return
+	}
+	if _, _, err := s.Repo.List(r.Context(), ListQuery{Limit: 1}); err != nil {
+		log.Print(err)
+		writeProblem(w, r, newProblem(http.StatusServiceUnavailable, "repositório indisponível"))
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// handleList responde com uma página de usuários; veja parseListQuery.
+func (s *Server) handleList(w http.ResponseWriter, r *http.Request) {
+	q, page, size, err := parseListQuery(r.URL.Query())
+	if err != nil {
+		writeProblem(w, r, newProblem(http.StatusBadRequest, err.Error()))
+		return
+	}
+	usuarios, total, err := s.Repo.List(r.Context(), q)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, UsuarioPage{
+		Dados: usuarios,
+		Meta:  PageMeta{Total: total, Pagina: page, Tamanho: size, Paginas: (total + size - 1) / size},
+	})
+}
+
+func (s *Server) handleGet(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	u, err := s.Repo.Get(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, u)
+}
+
+// handleCreate cadastra o usuário; o id do corpo, se houver, é ignorado.
+func (s *Server) handleCreate(w http.ResponseWriter, r *http.Request) {
+	var u Usuario
+	if !readJSON(w, r, &u) || !s.valid(w, r, &u) {
+		return
+	}
+	u, err := s.Repo.Create(r.Context(), u)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Location", "/usuarios/"+strconv.Itoa(u.ID))
+	writeJSON(w, http.StatusCreated, u)
+}
+
+// handleReplace substitui todos os campos do usuário. O id vem do caminho;
+// se vier também no corpo, precisa ser o mesmo.
+func (s *Server) handleReplace(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	var u Usuario
+	if !readJSON(w, r, &u) {
+		return
+	}
+	if u.ID != 0 && u.ID != id {
+		writeProblem(w, r, newProblem(http.StatusBadRequest, "o id do corpo difere do id do caminho"))
+		return
+	}
+	u.ID = id
+	if !s.valid(w, r, &u) {
+		return
+	}
+	u, err := s.Repo.Update(r.Context(), u)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, u)
+}
+
+// handlePatch altera só os campos presentes no corpo.
+func (s *Server) handlePatch(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	var p UsuarioPatch
+	if !readJSON(w, r, &p) {
+		return
+	}
+	u, err := s.Repo.Get(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	p.Apply(&u)
+	if !s.valid(w, r, &u) {
+		return
+	}
+	if u, err = s.Repo.Update(r.Context(), u); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, u)
+}
+
+func (s *Server) handleDelete(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	if err := s.Repo.Delete(r.Context(), id); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// pathID lê o {id} do caminho. Em caso de erro já responde 400.
+func pathID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || id <= 0 {
+		writeProblem(w, r, newProblem(http.StatusBadRequest, "id inválido: "+r.PathValue("id")))
+		return 0, false
+	}
+	return id, true
+}
+
+// readJSON decodifica o corpo em v, rejeitando campos desconhecidos. Em caso
+// de erro já responde 400.
+func readJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		p := newProblem(http.StatusBadRequest, "JSON inválido: "+err.Error())
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			p.Detail = "JSON inválido"
+			p.InvalidParams = []InvalidParam{{Name: typeErr.Field, Reason: "deve ser do tipo " + typeErr.Type.String()}}
+		}
+		writeProblem(w, r, p)
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
+
+// valid remove os espaços das pontas dos campos e os valida. Em caso de erro
+// já responde 422 com a lista dos campos inválidos.
+func (s *Server) valid(w http.ResponseWriter, r *http.Request, u *Usuario) bool {
+	u.Nome = strings.TrimSpace(u.Nome)
+	u.Email = strings.TrimSpace(u.Email)
+	u.Localizacao = strings.TrimSpace(u.Localizacao)
+
+	params := s.Validator.Usuario(*u)
+	if len(params) == 0 {
+		return true
+	}
+	writeProblem(w, r, Problem{
+		Type:          problemValidation,
+		Title:         "Dados inválidos",
+		Status:        http.StatusUnprocessableEntity,
+		Detail:        "Corrija os campos listados em invalid-params.",
+		InvalidParams: params,
+	})
+	return false
+}
+
+// writeError responde 404 e 409 para os erros dos repositórios e 500 para
+// os demais, sem expor o erro interno ao cliente.
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		writeProblem(w, r, newProblem(http.StatusNotFound, err.Error()))
+	case errors.Is(err, ErrEmailInUse):
+		writeProblem(w, r, Problem{
+			Type:          problemEmailInUse,
+			Title:         "E-mail já cadastrado",
+			Status:        http.StatusConflict,
+			Detail:        "Outro usuário já usa este e-mail.",
+			InvalidParams: []InvalidParam{{Name: "email", Reason: err.Error()}},
+		})
+	default:
+		log.Print(err)
+		writeProblem(w, r, newProblem(http.StatusInternalServerError, ""))
+	}
+}
@@ -0,0 +1,314 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// repositories cria cada implementação de UserRepository, vazia.
+var repositories = map[string]func(t *testing.T) UserRepository{
+	"memoria": func(t *testing.T) UserRepository { return NewMemoryRepository() },
+	"sqlite": func(t *testing.T) UserRepository {
+		db, err := OpenSQLite(filepath.Join(t.TempDir(), "usuarios.db"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { db.Close() })
+		return db
+	},
+}
+
+// forEachRepository roda test com um servidor de teste sobre cada
+// repositório.
+func forEachRepository(t *testing.T, test func(t *testing.T, url string)) {
+	for name, open := range repositories {
+		t.Run(name, func(t *testing.T) {
+			validator, err := NewValidator()
+			if err != nil {
+				t.Fatal(err)
+			}
+			api := &Server{Repo: open(t), Validator: validator}
+			srv := httptest.NewServer(api.Handler())
+			t.Cleanup(srv.Close)
+			test(t, srv.URL)
+		})
+	}
+}
+
+// do envia a requisição e confere o status. O corpo da resposta, se v não
+// for nil, é decodificado em v.
+func do(t *testing.T, method, url, body string, status int, v any) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != status {
+		t.Fatalf("%s %s = %d %s, quer %d", method, url, resp.StatusCode, b, status)
+	}
+	if v != nil {
+		if err := json.Unmarshal(b, v); err != nil {
+			t.Fatalf("%s %s: %v: %s", method, url, err, b)
+		}
+	}
+	return resp
+}
+
+// problem confere que a resposta é um problem+json com o status esperado.
+func problem(t *testing.T, method, url, body string, status int) Problem {
+	t.Helper()
+	var p Problem
+	resp := do(t, method, url, body, status, &p)
+	if ct := resp.Header.Get("Content-Type"); ct != "application/problem+json" {
+		t.Errorf("%s %s: Content-Type = %q", method, url, ct)
+	}
+	if p.Status != status {
+		t.Errorf("%s %s: status no corpo = %d, quer %d", method, url, p.Status, status)
+	}
+	return p
+}
+
+const ana = `{"nome": "Ana", "email": "ana@exemplo.com", "localizacao": "Curitiba"}`
+
+func TestCreateGet(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, url string) {
+		var created Usuario
+		resp := do(t, "POST", url+"/usuarios", ana, http.StatusCreated, &created)
+		want := Usuario{ID: 1, Nome: "Ana", Email: "ana@exemplo.com", Localizacao: "Curitiba"}
+		if created != want {
+			t.Errorf("criado = %+v, quer %+v", created, want)
+		}
+		if loc := resp.Header.Get("Location"); loc != "/usuarios/1" {
+			t.Errorf("Location = %q", loc)
+		}
+
+		var got Usuario
+		do(t, "GET", url+"/usuarios/1", "", http.StatusOK, &got)
+		if got != want {
+			t.Errorf("GET = %+v, quer %+v", got, want)
+		}
+
+		// Espaços nas pontas são removidos e o id do corpo é ignorado
+		do(t, "POST", url+"/usuarios", `{"id": 7, "nome": " Bia ", "email": "bia@exemplo.com"}`, http.StatusCreated, &created)
+		if created.ID != 2 || created.Nome != "Bia" {
+			t.Errorf("criado = %+v", created)
+		}
+	})
+}
+
+func TestUpdate(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, url string) {
+		do(t, "POST", url+"/usuarios", ana, http.StatusCreated, nil)
+
+		var got Usuario
+		do(t, "PUT", url+"/usuarios/1", `{"nome": "Ana Maria", "email": "ana@exemplo.com"}`, http.StatusOK, &got)
+		if want := (Usuario{ID: 1, Nome: "Ana Maria", Email: "ana@exemplo.com"}); got != want {
+			t.Errorf("PUT = %+v, quer %+v", got, want)
+		}
+
+		// O PATCH mantém os campos ausentes
+		do(t, "PATCH", url+"/usuarios/1", `{"localizacao": "Ponta Grossa"}`, http.StatusOK, &got)
+		do(t, "GET", url+"/usuarios/1", "", http.StatusOK, &got)
+		if want := (Usuario{ID: 1, Nome: "Ana Maria", Email: "ana@exemplo.com", Localizacao: "Ponta Grossa"}); got != want {
+			t.Errorf("depois do PATCH = %+v, quer %+v", got, want)
+		}
+
+		problem(t, "PUT", url+"/usuarios/1", `{"id": 2, "nome": "Ana", "email": "ana@exemplo.com"}`, http.StatusBadRequest)
+		p := problem(t, "PATCH", url+"/usuarios/1", `{"email": "ana"}`, http.StatusUnprocessableEntity)
+		if len(p.InvalidParams) != 1 || p.InvalidParams[0].Name != "email" {
+			t.Errorf("invalid-params = %+v", p.InvalidParams)
+		}
+	})
+}
+
+func TestDelete(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, url string) {
+		do(t, "POST", url+"/usuarios", ana, http.StatusCreated, nil)
+		do(t, "DELETE", url+"/usuarios/1", "", http.StatusNoContent, nil)
+		problem(t, "GET", url+"/usuarios/1", "", http.StatusNotFound)
+		problem(t, "DELETE", url+"/usuarios/1", "", http.StatusNotFound)
+	})
+}
+
+func TestNotFound(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, url string) {
+		p := problem(t, "GET", url+"/usuarios/42", "", http.StatusNotFound)
+		if p.Instance != "/usuarios/42" || p.Detail != ErrNotFound.Error() {
+			t.Errorf("problema = %+v", p)
+		}
+		problem(t, "PUT", url+"/usuarios/42", ana, http.StatusNotFound)
+		problem(t, "PATCH", url+"/usuarios/42", `{"nome": "Ana"}`, http.StatusNotFound)
+		problem(t, "DELETE", url+"/usuarios/42", "", http.StatusNotFound)
+		problem(t, "GET", url+"/usuarios/abc", "", http.StatusBadRequest)
+	})
+}
+
+func TestEmailConflict(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, url string) {
+		do(t, "POST", url+"/usuarios", ana, http.StatusCreated, nil)
+		do(t, "POST", url+"/usuarios", `{"nome": "Bia", "email": "bia@exemplo.com"}`, http.StatusCreated, nil)
+
+		// A comparação do e-mail ignora maiúsculas
+		p := problem(t, "POST", url+"/usuarios", `{"nome": "Outra Ana", "email": "ANA@exemplo.com"}`, http.StatusConflict)
+		if p.Type != problemEmailInUse || len(p.InvalidParams) != 1 || p.InvalidParams[0].Name != "email" {
+			t.Errorf("problema = %+v", p)
+		}
+		problem(t, "PUT", url+"/usuarios/2", `{"nome": "Bia", "email": "ana@exemplo.com"}`, http.StatusConflict)
+		problem(t, "PATCH", url+"/usuarios/2", `{"email": "Ana@Exemplo.com"}`, http.StatusConflict)
+
+		// O próprio e-mail continua valendo
+		do(t, "PUT", url+"/usuarios/1", `{"nome": "Ana", "email": "Ana@exemplo.com"}`, http.StatusOK, nil)
+	})
+}
+
+func TestList(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, url string) {
+		for _, u := range []string{
+			`{"nome": "Ana", "email": "ana@exemplo.com", "localizacao": "Paraná"}`,
+			`{"nome": "Álvaro", "email": "alvaro@exemplo.com", "localizacao": "São Paulo"}`,
+			`{"nome": "bruno", "email": "bruno@exemplo.com", "localizacao": "PARANÁ"}`,
+			`{"nome": "Élida", "email": "elida@exemplo.com", "localizacao": "Pará"}`,
+		} {
+			do(t, "POST", url+"/usuarios", u, http.StatusCreated, nil)
+		}
+		names := func(query string) []string {
+			t.Helper()
+			var page UsuarioPage
+			do(t, "GET", url+"/usuarios?"+query, "", http.StatusOK, &page)
+			var names []string
+			for _, u := range page.Dados {
+				names = append(names, u.Nome)
+			}
+			return names
+		}
+
+		// Maiúsculas acentuadas valem como as minúsculas nos dois repositórios
+		tests := []struct {
+			query string
+			want  []string
+		}{
+			{"localizacao=PARANÁ", []string{"Ana", "bruno"}},
+			{"localizacao.eq=paraná", []string{"Ana", "bruno"}},
+			{"localizacao=PARÁ", []string{"Élida"}},
+			{"nome=ÁLV", []string{"Álvaro"}},
+			{"sort=nome", []string{"Ana", "bruno", "Álvaro", "Élida"}},
+			{"sort=-nome", []string{"Élida", "Álvaro", "bruno", "Ana"}},
+			{"sort=localizacao,-id", []string{"bruno", "Ana", "Élida", "Álvaro"}},
+			{"sort=nome&page=2&size=3", []string{"Élida"}},
+		}
+		for _, tt := range tests {
+			if got := names(tt.query); !slices.Equal(got, tt.want) {
+				t.Errorf("GET /usuarios?%s = %v, quer %v", tt.query, got, tt.want)
+			}
+		}
+
+		for _, query := range []string{
+			"page=0",
+			"size=101",
+			"page=922337203685477581&size=100",
+			"page=99999999999999999999",
+			"sort=senha",
+			"cidade=x",
+		} {
+			problem(t, "GET", url+"/usuarios?"+query, "", http.StatusBadRequest)
+		}
+	})
+}
+
+func TestUnknownRoutes(t *testing.T) {
+	forEachRepository(t, func(t *testing.T, url string) {
+		for _, path := range []string{"/nada", "/", "/usuarios/1/extra"} {
+			if p := problem(t, "GET", url+path, "", http.StatusNotFound); p.Instance != path {
+				t.Errorf("GET %s: instance = %q", path, p.Instance)
+			}
+		}
+
+		tests := []struct {
+			method, path, allow string
+		}{
+			{"PUT", "/usuarios", "GET, HEAD, POST"},
+			{"DELETE", "/usuarios", "GET, HEAD, POST"},
+			{"POST", "/usuarios/1", "GET, HEAD, PUT, PATCH, DELETE"},
+			{"POST", "/healthz", "GET, HEAD"},
+		}
+		for _, tt := range tests {
+			var p Problem
+			resp := do(t, tt.method, url+tt.path, "", http.StatusMethodNotAllowed, &p)
+			if ct := resp.Header.Get("Content-Type"); ct != "application/problem+json" || p.Status != http.StatusMethodNotAllowed {
+				t.Errorf("%s %s: Content-Type = %q, problema = %+v", tt.method, tt.path, ct, p)
+			}
+			if got := resp.Header.Get("Allow"); got != tt.allow {
+				t.Errorf("%s %s: Allow = %q, quer %q", tt.method, tt.path, got, tt.allow)
+			}
+		}
+	})
+}
+
+// readyServer sobe o servidor sobre repo, ainda sem SetReady.
+func readyServer(t *testing.T, repo UserRepository) (*Server, string) {
+	t.Helper()
+	validator, err := NewValidator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	api := &Server{Repo: repo, Validator: validator}
+	srv := httptest.NewServer(api.Handler())
+	t.Cleanup(srv.Close)
+	return api, srv.URL
+}
+
+func TestReady(t *testing.T) {
+	api, url := readyServer(t, NewMemoryRepository())
+
+	// Antes do primeiro SetReady o servidor ainda está iniciando
+	problem(t, "GET", url+"/readyz", "", http.StatusServiceUnavailable)
+
+	// Pronto, encerrando e de volta; /healthz responde 200 o tempo todo
+	for _, ready := range []bool{true, false, true} {
+		api.SetReady(ready)
+		if ready {
+			var body map[string]string
+			if do(t, "GET", url+"/readyz", "", http.StatusOK, &body); body["status"] != "ok" {
+				t.Errorf("corpo = %v", body)
+			}
+		} else {
+			problem(t, "GET", url+"/readyz", "", http.StatusServiceUnavailable)
+		}
+		do(t, "GET", url+"/healthz", "", http.StatusOK, nil)
+	}
+}
+
+func TestReadyRepositoryDown(t *testing.T) {
+	db, err := OpenSQLite(filepath.Join(t.TempDir(), "usuarios.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	api, url := readyServer(t, db)
+	api.SetReady(true)
+	do(t, "GET", url+"/readyz", "", http.StatusOK, nil)
+
+	// Com o banco fechado o processo segue vivo, mas sai do balanceamento
+	db.Close()
+	if p := problem(t, "GET", url+"/readyz", "", http.StatusServiceUnavailable); p.Detail != "repositório indisponível" {
+		t.Errorf("detalhe = %q", p.Detail)
+	}
+	do(t, "GET", url+"/healthz", "", http.StatusOK, nil)
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Tamanho de página padrão e máximo da listagem.
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// textFields são os campos de texto do Usuario, usados nos filtros e na
+// ordenação.
+var textFields = map[string]func(Usuario) string{
+	"nome":        func(u Usuario) string { return u.Nome },
+	"email":       func(u Usuario) string { return u.Email },
+	"localizacao": func(u Usuario) string { return u.Localizacao },
+}
+
+// Filter seleciona os usuários cujo campo contém Value ou, com Exact, é
+// igual a ele. Maiúsculas e minúsculas não são diferenciadas.
+type Filter struct {
+	Field string
+	Value string
+	Exact bool
+}
+
+// SortKey ordena pelo campo Field, decrescente com Desc.
+type SortKey struct {
+	Field string
+	Desc  bool
+}
+
+// ListQuery descreve uma listagem. Os usuários que passam em todos os
+// filtros são ordenados por Sort e, no empate, por id; então Offset são
+// pulados e no máximo Limit retornados (zero retorna todos).
+type ListQuery struct {
+	Filters []Filter
+	Sort    []SortKey
+	Offset  int
+	Limit   int
+}
+
+// fold converte o texto para minúsculas, inclusive letras acentuadas, para
+// filtros e ordenação que não diferenciam maiúsculas.
+func fold(s string) string {
+	return strings.ToLower(s)
+}
+
+// compareFold compara a e b sem diferenciar maiúsculas.
+func compareFold(a, b string) int {
+	return strings.Compare(fold(a), fold(b))
+}
+
+// Match informa se u passa em todos os filtros.
+func (q ListQuery) Match(u Usuario) bool {
+	for _, f := range q.Filters {
+		v, want := fold(textFields[f.Field](u)), fold(f.Value)
+		if f.Exact && v != want || !f.Exact && !strings.Contains(v, want) {
+			return false
+		}
+	}
+	return true
+}
+
+// Compare compara dois usuários na ordem da listagem.
+func (q ListQuery) Compare(a, b Usuario) int {
+	for _, k := range q.Sort {
+		var c int
+		if k.Field == "id" {
+			c = cmp.Compare(a.ID, b.ID)
+		} else {
+			get := textFields[k.Field]
+			c = compareFold(get(a), get(b))
+		}
+		if k.Desc {
+			c = -c
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return cmp.Compare(a.ID, b.ID)
+}
+
+// PageMeta descreve a página devolvida pela listagem.
+type PageMeta struct {
+	Total   int `json:"total"` // usuários que passam nos filtros
+	Pagina  int `json:"pagina"`
+	Tamanho int `json:"tamanho"`
+	Paginas int `json:"paginas"`
+}
+
+// UsuarioPage é a resposta de GET /usuarios.
+type UsuarioPage struct {
+	Dados []Usuario `json:"dados"`
+	Meta  PageMeta  `json:"meta"`
+}
+
+// parseListQuery interpreta a query string da listagem:
+//
+//	page=2&size=50          página (a partir de 1) e tamanho
+//	nome=ana                nome contém "ana"; idem email e localizacao
+//	email.eq=ana@x.com      email igual a "ana@x.com"
+//	sort=localizacao,-nome  ordena por localizacao e depois nome decrescente
+//
+// Parâmetros desconhecidos são rejeitados.
+func parseListQuery(values url.Values) (q ListQuery, page, size int, err error) {
+	page, size = 1, defaultPageSize
+	for key, vs := range values {
+		v := vs[len(vs)-1]
+		field, exact := strings.CutSuffix(key, ".eq")
+		switch {
+		case key == "page":
+			if page, err = strconv.Atoi(v); err != nil || page < 1 {
+				return ListQuery{}, 0, 0, fmt.Errorf("page inválido: %q", v)
+			}
+		case key == "size":
+			if size, err = strconv.Atoi(v); err != nil || size < 1 || size > maxPageSize {
+				return ListQuery{}, 0, 0, fmt.Errorf("size deve estar entre 1 e %d", maxPageSize)
+			}
+		case key == "sort":
+			for _, name := range strings.Split(v, ",") {
+				k := SortKey{}
+				k.Field, k.Desc = strings.CutPrefix(name, "-")
+				if _, ok := textFields[k.Field]; !ok && k.Field != "id" {
+					return ListQuery{}, 0, 0, fmt.Errorf("não é possível ordenar por %q", k.Field)
+				}
+				q.Sort = append(q.Sort, k)
+			}
+		case textFields[field] != nil:
+			q.Filters = append(q.Filters, Filter{Field: field, Value: v, Exact: exact})
+		default:
+			return ListQuery{}, 0, 0, fmt.Errorf("parâmetro desconhecido %q", key)
+		}
+	}
+	// O deslocamento (page-1)*size precisa caber em um int
+	if page > math.MaxInt/size {
+		return ListQuery{}, 0, 0, fmt.Errorf("page deve ser no máximo %d", math.MaxInt/size)
+	}
+	q.Offset, q.Limit = (page-1)*size, size
+	return q, page, size, nil
+}
@@ -1,57 +1,120 @@
 package main
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
+	"flag"
 	"fmt"
-	"io/ioutil"
 	"log"
+	"net"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 )
 
-// Estrutura do usuário
-type Usuario struct {
-	ID          int    `json:"id"`
-	Nome        string `json:"nome"`
-	Email       string `json:"email"`
-	Localizacao string `json:"localizacao"`
-}
-
 func main() {
-	// Cria um usuário de exemplo
-	usuario := Usuario{
-		ID:          1,
-		Nome:        "Fulano de Tal",
-		Email:       "fulano@exemplo.com",
-		Localizacao: "Guarapuava, Paraná",
+	if err := run(); err != nil {
+		log.Fatal(err)
 	}
+}
 
-	// Manipulador do endpoint "/usuario"
-	http.HandleFunc("/usuario", func(w http.ResponseWriter, r *http.Request) {
-		// Define o cabeçalho Content-Type como "application/json"
-		w.Header().Set("Content-Type", "application/json")
-
-		// Codifica o usuário em JSON
-		json.NewEncoder(w).Encode(usuario)
-	})
+// run inicia o servidor e o encerra com SIGINT ou SIGTERM, esperando as
+// requisições em andamento por até 10 segundos.
+func run() error {
+	dbPath := flag.String("db", "", "arquivo SQLite dos usuários (vazio guarda em memória)")
+	registryPath := flag.String("registro", "portas.txt", "arquivo com as portas abertas (vazio não registra)")
+	flag.Parse()
 
 	// Verifica se a porta foi fornecida como argumento
-	if len(os.Args) != 2 {
-		fmt.Println("Uso: ./nomeprograma <porta>")
-		return
+	if flag.NArg() != 1 {
+		fmt.Println("Uso: ./nomeprograma [-db usuarios.db] [-registro portas.txt] <porta>")
+		fmt.Println("Com a porta 0 o sistema escolhe uma porta livre.")
+		return nil
 	}
 
-	// Obtém a porta do primeiro argumento
-	porta := ":" + os.Args[1]
-
-	// Inicia o servidor na porta especificada
-	fmt.Printf("Servidor iniciado em http://localhost%s/usuario\n", porta)
-	log.Fatal(http.ListenAndServe(porta, nil))
+	var repo UserRepository
+	if *dbPath == "" {
+		repo = NewMemoryRepository()
+	} else {
+		db, err := OpenSQLite(*dbPath)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		repo = db
+	}
+	if err := seed(context.Background(), repo); err != nil {
+		return err
+	}
+	validator, err := NewValidator()
+	if err != nil {
+		return err
+	}
 
-	// Cria um arquivo com a porta aberta
-	err := ioutil.WriteFile("portas.txt", []byte(porta), 0644)
+	// Abre a porta antes de registrá-la, para saber qual o sistema escolheu
+	// quando a porta pedida é 0.
+	ln, err := net.Listen("tcp", ":"+flag.Arg(0))
 	if err != nil {
-		log.Fatal(err)
+		return err
+	}
+	porta := ln.Addr().(*net.TCPAddr).Port
+	if *registryPath != "" {
+		registry := PortRegistry{Path: *registryPath}
+		if err := registry.Add(porta); err != nil {
+			ln.Close()
+			return err
+		}
+		defer func() {
+			if err := registry.Remove(porta); err != nil {
+				log.Print(err)
+			}
+		}()
+		fmt.Printf("Porta aberta salva em %s\n", *registryPath)
 	}
-	fmt.Println("Porta aberta salva em portas.txt")
+
+	api := &Server{Repo: repo, Validator: validator}
+	server := &http.Server{Handler: api.Handler(), ReadHeaderTimeout: 5 * time.Second}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	serveErr := make(chan error, 1)
+	go func() { serveErr <- server.Serve(ln) }()
+	api.SetReady(true)
+	fmt.Printf("Servidor iniciado em http://localhost:%d/usuarios\n", porta)
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+
+	// Sai do balanceamento antes de parar de aceitar conexões.
+	api.SetReady(false)
+	log.Print("Encerrando...")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	if err := <-serveErr; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// seed cadastra o usuário de exemplo quando o repositório está vazio.
+func seed(ctx context.Context, repo UserRepository) error {
+	_, total, err := repo.List(ctx, ListQuery{Limit: 1})
+	if err != nil || total > 0 {
+		return err
+	}
+	_, err = repo.Create(ctx, Usuario{
+		Nome:        "Fulano de Tal",
+		Email:       "fulano@exemplo.com",
+		Localizacao: "Guarapuava, Paraná",
+	})
+	return err
 }
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// MemoryRepository guarda os usuários em memória; eles se perdem ao
+// encerrar o programa.
+type MemoryRepository struct {
+	mu     sync.Mutex
+	users  map[int]Usuario
+	nextID int
+}
+
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{users: make(map[int]Usuario), nextID: 1}
+}
+
+func (m *MemoryRepository) List(ctx context.Context, q ListQuery) ([]Usuario, int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	list := []Usuario{}
+	for _, u := range m.users {
+		if q.Match(u) {
+			list = append(list, u)
+		}
+	}
+	slices.SortFunc(list, q.Compare)
+
+	total := len(list)
+	list = list[min(q.Offset, total):]
+	if q.Limit > 0 {
+		list = list[:min(q.Limit, len(list))]
+	}
+	return list, total, nil
+}
+
+func (m *MemoryRepository) Get(ctx context.Context, id int) (Usuario, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	u, ok := m.users[id]
+	if !ok {
+		return Usuario{}, ErrNotFound
+	}
+	return u, nil
+}
+
+func (m *MemoryRepository) Create(ctx context.Context, u Usuario) (Usuario, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.emailInUse(u.Email, 0) {
+		return Usuario{}, ErrEmailInUse
+	}
+	u.ID = m.nextID
+	m.nextID++
+	m.users[u.ID] = u
+	return u, nil
+}
+
+func (m *MemoryRepository) Update(ctx context.Context, u Usuario) (Usuario, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.users[u.ID]; !ok {
+		return Usuario{}, ErrNotFound
+	}
+	if m.emailInUse(u.Email, u.ID) {
+		return Usuario{}, ErrEmailInUse
+	}
+	m.users[u.ID] = u
+	return u, nil
+}
+
+func (m *MemoryRepository) Delete(ctx context.Context, id int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.users[id]; !ok {
+		return ErrNotFound
+	}
+	delete(m.users, id)
+	return nil
+}
+
+// emailInUse informa se outro usuário, que não o de ID except, já usa o
+// e-mail. A comparação ignora maiúsculas, como no SQLiteRepository.
+func (m *MemoryRepository) emailInUse(email string, except int) bool {
+	for _, u := range m.users {
+		if u.ID != except && strings.EqualFold(u.Email, email) {
+			return true
+		}
+	}
+	return false
+}
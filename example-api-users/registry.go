@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+)
+
+// PortRegistry é um arquivo com uma porta aberta por linha (":8080"),
+// compartilhado por todas as instâncias do servidor. Cada instância inclui a
+// sua ao iniciar e a retira ao encerrar.
+type PortRegistry struct {
+	Path string
+}
+
+// Add inclui a porta no registro, se ainda não estiver lá.
+func (r PortRegistry) Add(port int) error {
+	entry := fmt.Sprintf(":%d", port)
+	return r.update(func(entries []string) []string {
+		if slices.Contains(entries, entry) {
+			return entries
+		}
+		return append(entries, entry)
+	})
+}
+
+// Remove retira a porta do registro.
+func (r PortRegistry) Remove(port int) error {
+	entry := fmt.Sprintf(":%d", port)
+	return r.update(func(entries []string) []string {
+		return slices.DeleteFunc(entries, func(e string) bool { return e == entry })
+	})
+}
+
+// update lê o registro, aplica change e o regrava atomicamente. Um arquivo
+// de trava evita que duas instâncias percam a alteração uma da outra.
+func (r PortRegistry) update(change func([]string) []string) error {
+	unlock, err := lockFile(r.Path + ".lock")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	var entries []string
+	b, err := os.ReadFile(r.Path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	sc := bufio.NewScanner(bytes.NewReader(b))
+	for sc.Scan() {
+		if line := sc.Text(); line != "" {
+			entries = append(entries, line)
+		}
+	}
+
+	entries = change(entries)
+	var buf bytes.Buffer
+	for _, e := range entries {
+		fmt.Fprintln(&buf, e)
+	}
+
+	// Grava ao lado e renomeia, para quem lê o registro sem a trava ver a
+	// versão anterior ou a nova inteira. Um nome fixo basta: só quem tem a
+	// trava escreve.
+	tmp := r.Path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, r.Path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// entries lê o registro como a lista de linhas não vazias.
+func entries(t *testing.T, path string) []string {
+	t.Helper()
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Fields(string(b))
+}
+
+func TestPortRegistry(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "portas.txt")
+	// Duas instâncias do servidor usando o mesmo registro
+	a, b := PortRegistry{Path: path}, PortRegistry{Path: path}
+
+	steps := []struct {
+		name string
+		do   func() error
+		want []string
+	}{
+		{"primeira", func() error { return a.Add(8080) }, []string{":8080"}},
+		{"repetida", func() error { return a.Add(8080) }, []string{":8080"}},
+		{"outra instância", func() error { return b.Add(8081) }, []string{":8080", ":8081"}},
+		{"remove só a sua", func() error { return a.Remove(8080) }, []string{":8081"}},
+		{"remove de novo", func() error { return a.Remove(8080) }, []string{":8081"}},
+		{"porta que nunca entrou", func() error { return b.Remove(9999) }, []string{":8081"}},
+		{"última", func() error { return b.Remove(8081) }, nil},
+	}
+	for _, s := range steps {
+		if err := s.do(); err != nil {
+			t.Fatalf("%s: %v", s.name, err)
+		}
+		if got := entries(t, path); !slices.Equal(got, s.want) {
+			t.Errorf("%s: registro = %q, quer %q", s.name, got, s.want)
+		}
+	}
+
+	// O temporário da gravação não fica para trás
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("temporário deixado no diretório: %v", err)
+	}
+}
+
+func TestPortRegistryKeepsOtherEntries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "portas.txt")
+	// Linhas de outras instâncias, com uma linha em branco no meio
+	if err := os.WriteFile(path, []byte(":3000\n\n:3001\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	r := PortRegistry{Path: path}
+	if err := r.Add(8080); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Remove(3000); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := entries(t, path), []string{":3001", ":8080"}; !slices.Equal(got, want) {
+		t.Errorf("registro = %q, quer %q", got, want)
+	}
+}
+
+func TestPortRegistryConcurrent(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("sem trava fora dos sistemas Unix")
+	}
+	path := filepath.Join(t.TempDir(), "portas.txt")
+
+	// Instâncias subindo juntas não perdem a porta uma da outra
+	var wg sync.WaitGroup
+	var want []string
+	for port := 9000; port < 9020; port++ {
+		want = append(want, fmt.Sprintf(":%d", port))
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := (PortRegistry{Path: path}).Add(port); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	got := entries(t, path)
+	slices.Sort(got)
+	if !slices.Equal(got, want) {
+		t.Errorf("registro = %q, quer %q", got, want)
+	}
+}
+
+func TestPortRegistryMissingDir(t *testing.T) {
+	r := PortRegistry{Path: filepath.Join(t.TempDir(), "nao-existe", "portas.txt")}
+	if err := r.Add(8080); err == nil {
+		t.Error("Add gravou em diretório inexistente")
+	}
+}
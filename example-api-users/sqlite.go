@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS usuarios (
+	id          INTEGER PRIMARY KEY AUTOINCREMENT,
+	nome        TEXT NOT NULL,
+	email       TEXT NOT NULL UNIQUE COLLATE NOCASE,
+	localizacao TEXT NOT NULL
+)`
+
+// lower() e COLLATE NOCASE do SQLite só tratam letras ASCII. Os filtros e a
+// ordenação usam no lugar go_lower e go_nocase, feitos com fold, para que
+// "PARANÁ" encontre "Paraná" e a ordem seja a mesma do MemoryRepository.
+func init() {
+	sqlite.MustRegisterDeterministicScalarFunction("go_lower", 1,
+		func(ctx *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+			if s, ok := args[0].(string); ok {
+				return fold(s), nil
+			}
+			return args[0], nil
+		})
+	sqlite.MustRegisterCollationUtf8("go_nocase", compareFold)
+}
+
+// SQLiteRepository guarda os usuários em um banco SQLite.
+type SQLiteRepository struct {
+	db *sql.DB
+}
+
+// OpenSQLite abre (ou cria) o banco em path e a tabela usuarios.
+func OpenSQLite(path string) (*SQLiteRepository, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+	// Uma conexão só: o SQLite não aceita escritas em paralelo.
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &SQLiteRepository{db: db}, nil
+}
+
+func (s *SQLiteRepository) Close() error {
+	return s.db.Close()
+}
+
+func (s *SQLiteRepository) List(ctx context.Context, q ListQuery) ([]Usuario, int, error) {
+	// Os nomes de campo já foram validados em parseListQuery; só os valores
+	// vão como parâmetros.
+	var where []string
+	var args []any
+	for _, f := range q.Filters {
+		if f.Exact {
+			where = append(where, "go_lower("+f.Field+") = ?")
+		} else {
+			where = append(where, "instr(go_lower("+f.Field+"), ?) > 0")
+		}
+		args = append(args, fold(f.Value))
+	}
+	cond := ""
+	if len(where) > 0 {
+		cond = " WHERE " + strings.Join(where, " AND ")
+	}
+
+	var total int
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM usuarios`+cond, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	var order []string
+	for _, k := range q.Sort {
+		col := k.Field
+		if col != "id" {
+			col += " COLLATE go_nocase"
+		}
+		if k.Desc {
+			col += " DESC"
+		}
+		order = append(order, col)
+	}
+	order = append(order, "id")
+	limit := q.Limit
+	if limit <= 0 {
+		limit = -1 // sem limite
+	}
+	query := `SELECT id, nome, email, localizacao FROM usuarios` + cond +
+		` ORDER BY ` + strings.Join(order, ", ") + ` LIMIT ? OFFSET ?`
+	rows, err := s.db.QueryContext(ctx, query, append(args, limit, q.Offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	list := []Usuario{}
+	for rows.Next() {
+		var u Usuario
+		if err := rows.Scan(&u.ID, &u.Nome, &u.Email, &u.Localizacao); err != nil {
+			return nil, 0, err
+		}
+		list = append(list, u)
+	}
+	return list, total, rows.Err()
+}
+
+func (s *SQLiteRepository) Get(ctx context.Context, id int) (Usuario, error) {
+	u := Usuario{ID: id}
+	err := s.db.QueryRowContext(ctx, `SELECT nome, email, localizacao FROM usuarios WHERE id = ?`, id).
+		Scan(&u.Nome, &u.Email, &u.Localizacao)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Usuario{}, ErrNotFound
+	}
+	return u, err
+}
+
+func (s *SQLiteRepository) Create(ctx context.Context, u Usuario) (Usuario, error) {
+	res, err := s.db.ExecContext(ctx, `INSERT INTO usuarios (nome, email, localizacao) VALUES (?, ?, ?)`,
+		u.Nome, u.Email, u.Localizacao)
+	if err != nil {
+		return Usuario{}, sqliteError(err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return Usuario{}, err
+	}
+	u.ID = int(id)
+	return u, nil
+}
+
+func (s *SQLiteRepository) Update(ctx context.Context, u Usuario) (Usuario, error) {
+	res, err := s.db.ExecContext(ctx, `UPDATE usuarios SET nome = ?, email = ?, localizacao = ? WHERE id = ?`,
+		u.Nome, u.Email, u.Localizacao, u.ID)
+	if err != nil {
+		return Usuario{}, sqliteError(err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return Usuario{}, err
+	} else if n == 0 {
+		return Usuario{}, ErrNotFound
+	}
+	return u, nil
+}
+
+func (s *SQLiteRepository) Delete(ctx context.Context, id int) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM usuarios WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// sqliteError traduz a violação do índice único de email em ErrEmailInUse.
+func sqliteError(err error) error {
+	var e *sqlite.Error
+	if errors.As(err, &e) && e.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE {
+		return ErrEmailInUse
+	}
+	return err
+}
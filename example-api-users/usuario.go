@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"errors"
+)
+
+// Estrutura do usuário
+type Usuario struct {
+	ID          int    `json:"id"`
+	Nome        string `json:"nome" validate:"required,max=100"`
+	Email       string `json:"email" validate:"required,max=254,email"`
+	Localizacao string `json:"localizacao" validate:"max=100"`
+}
+
+// UsuarioPatch é o corpo do PATCH: só os campos presentes são alterados.
+type UsuarioPatch struct {
+	Nome        *string `json:"nome"`
+	Email       *string `json:"email"`
+	Localizacao *string `json:"localizacao"`
+}
+
+// Apply aplica as alterações presentes em u.
+func (p UsuarioPatch) Apply(u *Usuario) {
+	if p.Nome != nil {
+		u.Nome = *p.Nome
+	}
+	if p.Email != nil {
+		u.Email = *p.Email
+	}
+	if p.Localizacao != nil {
+		u.Localizacao = *p.Localizacao
+	}
+}
+
+// Erros dos repositórios, respondidos com 404 e 409.
+var (
+	ErrNotFound   = errors.New("usuário não encontrado")
+	ErrEmailInUse = errors.New("e-mail já cadastrado")
+)
+
+// UserRepository guarda os usuários. O e-mail é único entre eles.
+type UserRepository interface {
+	// List retorna os usuários selecionados por q e o total dos que passam
+	// nos filtros, sem considerar Offset e Limit.
+	List(ctx context.Context, q ListQuery) ([]Usuario, int, error)
+	// Get retorna o usuário ou ErrNotFound.
+	Get(ctx context.Context, id int) (Usuario, error)
+	// Create ignora u.ID e retorna o usuário com o ID atribuído.
+	Create(ctx context.Context, u Usuario) (Usuario, error)
+	// Update substitui o usuário de ID u.ID.
+	Update(ctx context.Context, u Usuario) (Usuario, error)
+	// Delete apaga o usuário ou retorna ErrNotFound.
+	Delete(ctx context.Context, id int) error
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/locales/pt_BR"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	pt_translations "github.com/go-playground/validator/v10/translations/pt"
+)
+
+// Validator confere os dados do Usuario pelas tags validate, com mensagens
+// em português e os nomes de campo do JSON.
+type Validator struct {
+	validate *validator.Validate
+	trans    ut.Translator
+}
+
+func NewValidator() (*Validator, error) {
+	validate := validator.New()
+	validate.RegisterTagNameFunc(func(f reflect.StructField) string {
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		return name
+	})
+
+	pt := pt_BR.New()
+	trans, _ := ut.New(pt, pt).GetTranslator("pt_BR")
+	if err := pt_translations.RegisterDefaultTranslations(validate, trans); err != nil {
+		return nil, err
+	}
+
+	// Traduções personalizadas
+	translations := map[string]string{
+		"required": "{0} é obrigatório",
+		"max":      "{0} não pode ter mais de {1} caracteres",
+		"email":    "{0} deve ser um e-mail válido",
+	}
+	for tag, message := range translations {
+		if err := validate.RegisterTranslation(tag, trans, func(ut ut.Translator) error {
+			return ut.Add(tag, message, true)
+		}, func(ut ut.Translator, fe validator.FieldError) string {
+			t, _ := ut.T(fe.Tag(), fe.Field(), fe.Param())
+			return t
+		}); err != nil {
+			return nil, err
+		}
+	}
+	return &Validator{validate: validate, trans: trans}, nil
+}
+
+// Usuario retorna um InvalidParam para cada regra violada por u; nenhum se
+// u for válido. A unicidade do e-mail fica a cargo do repositório.
+func (v *Validator) Usuario(u Usuario) []InvalidParam {
+	err := v.validate.Struct(u)
+	if err == nil {
+		return nil
+	}
+	errs, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return []InvalidParam{{Name: "", Reason: err.Error()}}
+	}
+	params := make([]InvalidParam, len(errs))
+	for i, fe := range errs {
+		params[i] = InvalidParam{Name: fe.Field(), Reason: fe.Translate(v.trans)}
+	}
+	return params
+}
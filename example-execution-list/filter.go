@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Filter define os critérios de seleção de processos. Campos vazios não filtram.
+type Filter struct {
+	User  string
+	Name  string
+	Match *regexp.Regexp
+}
+
+// Keep informa se o processo atende a todos os critérios do filtro.
+func (f Filter) Keep(p Process) bool {
+	if f.User != "" && p.User != f.User {
+		return false
+	}
+	if f.Name != "" && !strings.Contains(p.Name, f.Name) {
+		return false
+	}
+	if f.Match != nil && !f.Match.MatchString(p.Command) {
+		return false
+	}
+	return true
+}
+
+// Apply retorna apenas os processos que atendem ao filtro.
+func (f Filter) Apply(processes []Process) []Process {
+	var kept []Process
+	for _, p := range processes {
+		if f.Keep(p) {
+			kept = append(kept, p)
+		}
+	}
+	return kept
+}
+
+// sortKeys mapeia o nome aceito em -sort para a função de comparação.
+// CPU e memória são ordenados do maior para o menor, como em `ps --sort=-%cpu`.
+var sortKeys = map[string]func(a, b Process) bool{
+	"pid": func(a, b Process) bool { return a.PID < b.PID },
+	"cpu": func(a, b Process) bool { return a.CPUPercent > b.CPUPercent },
+	"mem": func(a, b Process) bool { return a.RSS > b.RSS },
+}
+
+// SortProcesses ordena os processos pela chave informada (pid, cpu ou mem).
+func SortProcesses(processes []Process, key string) error {
+	less, ok := sortKeys[key]
+	if !ok {
+		return fmt.Errorf("ordenação desconhecida %q (use pid, cpu ou mem)", key)
+	}
+	sort.SliceStable(processes, func(i, j int) bool { return less(processes[i], processes[j]) })
+	return nil
+}
@@ -1,3 +1,3 @@
-module main
+module example-execution-list
 
 go 1.22.6
@@ -1,24 +1,205 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"flag"
 	"fmt"
-	"os/exec"
+	"os"
+	"os/signal"
+	"regexp"
+	"slices"
 	"strings"
+	"syscall"
+	"time"
 )
 
 func main() {
-	// Comando para listar os processos (Linux/macOS)
-	cmd := exec.Command("ps", "aux")
+	os.Exit(run())
+}
+
+// Flags que não se aplicam a cada modo; usá-las é um erro em vez de serem
+// ignoradas em silêncio.
+var unsupportedFlags = map[string][]string{
+	"lista": {"root", "collapse", "interval", "rss-growth"},
+	"tree":  {"sort", "columns", "interval", "rss-growth"},
+	"watch": {"sort", "columns", "root", "collapse"},
+}
+
+// Formatos aceitos por -format em cada modo.
+var modeFormats = map[string][]string{
+	"lista": {"table", "csv", "jsonl"},
+	"tree":  {"table", "json"},
+	"watch": {"table", "jsonl"},
+}
+
+// run executa o programa e retorna o código de saída: 1 para falhas na
+// execução e 2 para argumentos inválidos.
+func run() int {
+	if len(os.Args) > 1 && os.Args[1] == "serve" {
+		if err := runServe(os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, "Erro:", err)
+			return 1
+		}
+		return 0
+	}
+
+	procRoot := flag.String("proc", "/proc", "raiz do sistema de arquivos /proc")
+	userName := flag.String("user", "", "mostra apenas processos deste usuário")
+	name := flag.String("name", "", "mostra apenas processos cujo nome contém este texto")
+	match := flag.String("match", "", "expressão regular aplicada à linha de comando")
+	sortKey := flag.String("sort", "pid", "ordenação: pid, cpu ou mem")
+	tree := flag.Bool("tree", false, "mostra os processos em árvore, como o pstree")
+	treeRoot := flag.String("root", "", "com -tree, começa a árvore no PID ou nome informado")
+	collapse := flag.Bool("collapse", false, "com -tree, agrupa irmãos idênticos")
+	watch := flag.Bool("watch", false, "amostra os processos periodicamente e mostra o que mudou")
+	interval := flag.Duration("interval", 2*time.Second, "com -watch, intervalo entre amostras")
+	rssGrowth := flag.Uint64("rss-growth", 10240, "com -watch, crescimento de RSS em KB que gera alerta (0 desativa)")
+	format := flag.String("format", "table", "formato de saída: table, csv ou jsonl (com -tree: table ou json; com -watch: table ou jsonl)")
+	columnList := flag.String("columns", defaultColumns, "colunas da listagem, separadas por vírgula: "+columnNames())
+	flag.Parse()
+
+	mode := "lista"
+	switch {
+	case *tree && *watch:
+		fmt.Fprintln(os.Stderr, "Erro: use -tree ou -watch, não os dois")
+		return 2
+	case *tree:
+		mode = "tree"
+	case *watch:
+		mode = "watch"
+	}
+	set := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	if err := checkFlags(mode, set, *format); err != nil {
+		fmt.Fprintln(os.Stderr, "Erro:", err)
+		return 2
+	}
+	if mode == "watch" && *interval <= 0 {
+		fmt.Fprintln(os.Stderr, "Erro: -interval deve ser maior que zero")
+		return 2
+	}
+
+	filter := Filter{User: *userName, Name: *name}
+	if *match != "" {
+		re, err := regexp.Compile(*match)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Erro: expressão regular inválida:", err)
+			return 2
+		}
+		filter.Match = re
+	}
+
+	fs := NewProcFS(*procRoot)
+
+	if *watch {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		// O aviso vai para a saída de erro: com -format jsonl, toda linha de
+		// stdout precisa ser um objeto JSON
+		fmt.Fprintf(os.Stderr, "Monitorando processos a cada %s (Ctrl+C para sair)\n", *interval)
+		report := func(events []Event) error { return WriteEvents(os.Stdout, events) }
+		if *format == "jsonl" {
+			enc := json.NewEncoder(os.Stdout)
+			report = func(events []Event) error {
+				for _, e := range events {
+					if err := enc.Encode(e); err != nil {
+						return err
+					}
+				}
+				return nil
+			}
+		}
+		if err := Watch(ctx, fs, filter, *interval, NewWatcher(*rssGrowth*1024), report); err != nil {
+			fmt.Fprintln(os.Stderr, "Erro:", err)
+			return 1
+		}
+		return 0
+	}
+
+	// Lê os processos diretamente de /proc (somente Linux)
+	processes, err := fs.Processes()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Erro ao ler processos:", err)
+		return 1
+	}
+
+	if *tree {
+		if err := printTree(processes, filter, *treeRoot, *collapse, *format); err != nil {
+			fmt.Fprintln(os.Stderr, "Erro:", err)
+			return 1
+		}
+		return 0
+	}
+
+	processes = filter.Apply(processes)
+	if err := SortProcesses(processes, *sortKey); err != nil {
+		fmt.Fprintln(os.Stderr, "Erro:", err)
+		return 2
+	}
 
-	// No Windows, use: cmd := exec.Command("tasklist")
+	if err := printProcesses(processes, *format, *columnList); err != nil {
+		fmt.Fprintln(os.Stderr, "Erro:", err)
+		return 1
+	}
+	return 0
+}
 
-	output, err := cmd.Output()
+// checkFlags rejeita flags definidas que não se aplicam ao modo (lista,
+// tree ou watch) e formatos que o modo não suporta.
+func checkFlags(mode string, set map[string]bool, format string) error {
+	for _, name := range unsupportedFlags[mode] {
+		if set[name] {
+			return fmt.Errorf("-%s não se aplica ao modo %s", name, mode)
+		}
+	}
+	if !slices.Contains(modeFormats[mode], format) {
+		return fmt.Errorf("formato %q não suportado no modo %s (use %s)", format, mode, strings.Join(modeFormats[mode], ", "))
+	}
+	return nil
+}
+
+// printProcesses escreve a listagem no formato e com as colunas escolhidas.
+func printProcesses(processes []Process, format, columnList string) error {
+	cols, err := SelectColumns(columnList)
 	if err != nil {
-		panic(err)
+		return err
+	}
+	enc, err := NewEncoder(format, os.Stdout, cols)
+	if err != nil {
+		return err
+	}
+	for _, p := range processes {
+		if err := enc.Encode(p); err != nil {
+			return err
+		}
+	}
+	return enc.Flush()
+}
+
+// printTree monta a árvore, aplica raiz, filtro e agrupamento e a escreve em texto ou JSON.
+func printTree(processes []Process, filter Filter, root string, collapse bool, format string) error {
+	roots := BuildTree(processes)
+	if root != "" {
+		roots = FindRoots(roots, root)
+		if len(roots) == 0 {
+			return fmt.Errorf("nenhum processo encontrado para %q", root)
+		}
+	}
+	roots = Prune(roots, filter.Keep)
+	if collapse {
+		roots = Collapse(roots)
 	}
 
-	lines := strings.Split(string(output), "\n")
-	for _, line := range lines {
-		fmt.Println(line)
+	switch format {
+	case "table":
+		return WriteTree(os.Stdout, roots)
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(roots)
+	default:
+		return fmt.Errorf("formato %q não suportado com -tree (use table ou json)", format)
 	}
 }
@@ -0,0 +1,32 @@
+package main
+
+import "testing"
+
+func TestCheckFlags(t *testing.T) {
+	tests := []struct {
+		mode   string
+		set    []string
+		format string
+		ok     bool
+	}{
+		{"lista", []string{"sort", "columns"}, "csv", true},
+		{"lista", []string{"root"}, "table", false},
+		{"lista", nil, "json", false},
+		{"tree", []string{"root", "collapse"}, "json", true},
+		{"tree", []string{"columns"}, "table", false},
+		{"tree", nil, "csv", false},
+		{"watch", []string{"interval", "rss-growth"}, "jsonl", true},
+		{"watch", []string{"sort"}, "table", false},
+		{"watch", nil, "csv", false},
+	}
+	for _, tt := range tests {
+		set := make(map[string]bool)
+		for _, name := range tt.set {
+			set[name] = true
+		}
+		err := checkFlags(tt.mode, set, tt.format)
+		if (err == nil) != tt.ok {
+			t.Errorf("checkFlags(%s, %v, %s) = %v", tt.mode, tt.set, tt.format, err)
+		}
+	}
+}
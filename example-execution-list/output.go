@@ -0,0 +1,162 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// column descreve um campo de Process que pode ser selecionado na saída.
+// Text é usado na tabela; Raw é usado no CSV e no JSON, sem formatação para leitura humana.
+type column struct {
+	Name   string
+	Header string
+	Text   func(Process) string
+	Raw    func(Process) any
+}
+
+var columns = []column{
+	{"user", "USER", func(p Process) string { return p.User }, func(p Process) any { return p.User }},
+	{"uid", "UID", func(p Process) string { return strconv.Itoa(p.UID) }, func(p Process) any { return p.UID }},
+	{"pid", "PID", func(p Process) string { return strconv.Itoa(p.PID) }, func(p Process) any { return p.PID }},
+	{"ppid", "PPID", func(p Process) string { return strconv.Itoa(p.PPID) }, func(p Process) any { return p.PPID }},
+	{"cpu", "%CPU", func(p Process) string { return strconv.FormatFloat(p.CPUPercent, 'f', 1, 64) }, func(p Process) any { return p.CPUPercent }},
+	{"rss", "RSS(KB)", func(p Process) string { return strconv.FormatUint(p.RSS/1024, 10) }, func(p Process) any { return p.RSS / 1024 }},
+	{"state", "STAT", func(p Process) string { return p.State }, func(p Process) any { return p.State }},
+	{"start", "START", func(p Process) string { return p.StartTime.Format("15:04") }, func(p Process) any { return p.StartTime.Format(time.RFC3339) }},
+	{"time", "TIME", func(p Process) string { return p.CPUTime.Truncate(time.Second).String() }, func(p Process) any { return p.CPUTime.Seconds() }},
+	{"name", "NAME", func(p Process) string { return p.Name }, func(p Process) any { return p.Name }},
+	{"command", "COMMAND", func(p Process) string { return p.Command }, func(p Process) any { return p.Command }},
+}
+
+// defaultColumns reproduz as colunas do `ps aux`.
+const defaultColumns = "user,pid,ppid,cpu,rss,state,start,time,command"
+
+// SelectColumns converte uma lista separada por vírgulas nas colunas correspondentes.
+func SelectColumns(list string) ([]column, error) {
+	var selected []column
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		found := false
+		for _, c := range columns {
+			if c.Name == name {
+				selected = append(selected, c)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("coluna desconhecida %q (disponíveis: %s)", name, columnNames())
+		}
+	}
+	return selected, nil
+}
+
+func columnNames() string {
+	names := make([]string, len(columns))
+	for i, c := range columns {
+		names[i] = c.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+// Encoder escreve processos em um formato de saída.
+// Flush deve ser chamado depois do último processo.
+type Encoder interface {
+	Encode(p Process) error
+	Flush() error
+}
+
+// NewEncoder cria o encoder do formato informado: table, csv ou jsonl.
+func NewEncoder(format string, w io.Writer, cols []column) (Encoder, error) {
+	switch format {
+	case "table":
+		return newTableEncoder(w, cols)
+	case "csv":
+		return newCSVEncoder(w, cols)
+	case "jsonl":
+		return &jsonlEncoder{enc: json.NewEncoder(w), cols: cols}, nil
+	default:
+		return nil, fmt.Errorf("formato desconhecido %q (use table, csv ou jsonl)", format)
+	}
+}
+
+// tableEncoder escreve uma tabela alinhada, com cabeçalho, como o ps.
+type tableEncoder struct {
+	tw   *tabwriter.Writer
+	cols []column
+}
+
+func newTableEncoder(w io.Writer, cols []column) (*tableEncoder, error) {
+	e := &tableEncoder{tw: tabwriter.NewWriter(w, 0, 0, 2, ' ', 0), cols: cols}
+	headers := make([]string, len(cols))
+	for i, c := range cols {
+		headers[i] = c.Header
+	}
+	_, err := fmt.Fprintln(e.tw, strings.Join(headers, "\t"))
+	return e, err
+}
+
+func (e *tableEncoder) Encode(p Process) error {
+	values := make([]string, len(e.cols))
+	for i, c := range e.cols {
+		values[i] = c.Text(p)
+	}
+	_, err := fmt.Fprintln(e.tw, strings.Join(values, "\t"))
+	return err
+}
+
+func (e *tableEncoder) Flush() error {
+	return e.tw.Flush()
+}
+
+// csvEncoder escreve CSV com uma linha de cabeçalho com os nomes das colunas.
+type csvEncoder struct {
+	w    *csv.Writer
+	cols []column
+}
+
+func newCSVEncoder(w io.Writer, cols []column) (*csvEncoder, error) {
+	e := &csvEncoder{w: csv.NewWriter(w), cols: cols}
+	headers := make([]string, len(cols))
+	for i, c := range cols {
+		headers[i] = c.Name
+	}
+	return e, e.w.Write(headers)
+}
+
+func (e *csvEncoder) Encode(p Process) error {
+	record := make([]string, len(e.cols))
+	for i, c := range e.cols {
+		record[i] = fmt.Sprint(c.Raw(p))
+	}
+	return e.w.Write(record)
+}
+
+func (e *csvEncoder) Flush() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// jsonlEncoder escreve um objeto JSON por linha apenas com as colunas selecionadas.
+type jsonlEncoder struct {
+	enc  *json.Encoder
+	cols []column
+}
+
+func (e *jsonlEncoder) Encode(p Process) error {
+	obj := make(map[string]any, len(e.cols))
+	for _, c := range e.cols {
+		obj[c.Name] = c.Raw(p)
+	}
+	return e.enc.Encode(obj)
+}
+
+func (e *jsonlEncoder) Flush() error {
+	return nil
+}
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// clockTicks é o valor de USER_HZ usado pelo kernel Linux para os campos de tempo de /proc/<pid>/stat.
+const clockTicks = 100
+
+// Process representa um processo lido de /proc.
+type Process struct {
+	PID        int           `json:"pid"`
+	PPID       int           `json:"ppid"`
+	UID        int           `json:"uid"`
+	User       string        `json:"user"`
+	State      string        `json:"state"`
+	Name       string        `json:"name"`
+	Command    string        `json:"command"`
+	RSS        uint64        `json:"rss"`
+	CPUTime    time.Duration `json:"cpu_time"`
+	CPUPercent float64       `json:"cpu_percent"`
+	StartTime  time.Time     `json:"start_time"`
+}
+
+// ProcFS lê processos a partir de uma árvore no formato de /proc.
+// Root pode apontar para uma cópia falsa de /proc para testes.
+type ProcFS struct {
+	Root       string
+	ClockTicks int64
+	PageSize   int64
+	Now        func() time.Time
+	LookupUser func(uid int) string
+}
+
+// NewProcFS cria um ProcFS com os valores padrão do sistema para a raiz informada.
+func NewProcFS(root string) *ProcFS {
+	var mu sync.Mutex
+	users := make(map[int]string)
+	return &ProcFS{
+		Root:       root,
+		ClockTicks: clockTicks,
+		PageSize:   int64(os.Getpagesize()),
+		Now:        time.Now,
+		LookupUser: func(uid int) string {
+			mu.Lock()
+			defer mu.Unlock()
+			if name, ok := users[uid]; ok {
+				return name
+			}
+			name := strconv.Itoa(uid)
+			if u, err := user.LookupId(name); err == nil {
+				name = u.Username
+			}
+			users[uid] = name
+			return name
+		},
+	}
+}
+
+// Processes lista todos os processos encontrados na raiz, ordenados por PID.
+// Processos que terminam durante a leitura são ignorados.
+func (fs *ProcFS) Processes() ([]Process, error) {
+	entries, err := os.ReadDir(fs.Root)
+	if err != nil {
+		return nil, err
+	}
+
+	bootTime, err := fs.bootTime()
+	if err != nil {
+		return nil, err
+	}
+	now := fs.Now()
+
+	var processes []Process
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil || !entry.IsDir() {
+			continue
+		}
+
+		p, err := fs.readProcess(pid, bootTime, now)
+		if err != nil {
+			if vanished(err) {
+				continue
+			}
+			return nil, fmt.Errorf("processo %d: %w", pid, err)
+		}
+		processes = append(processes, p)
+	}
+
+	sort.Slice(processes, func(i, j int) bool { return processes[i].PID < processes[j].PID })
+	return processes, nil
+}
+
+// bootTime lê o horário de boot (btime) de /proc/stat.
+func (fs *ProcFS) bootTime() (time.Time, error) {
+	data, err := os.ReadFile(filepath.Join(fs.Root, "stat"))
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && fields[0] == "btime" {
+			secs, err := strconv.ParseInt(fields[1], 10, 64)
+			if err != nil {
+				return time.Time{}, fmt.Errorf("btime inválido: %w", err)
+			}
+			return time.Unix(secs, 0), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("btime não encontrado em %s", filepath.Join(fs.Root, "stat"))
+}
+
+// errVanished indica que os arquivos do processo vieram vazios, o que
+// acontece quando ele termina no meio da leitura.
+var errVanished = errors.New("processo terminou durante a leitura")
+
+// vanished informa se err vem de um processo que terminou enquanto era lido:
+// o diretório some (ENOENT), a leitura falha com ESRCH ou volta vazia.
+func vanished(err error) bool {
+	return errors.Is(err, os.ErrNotExist) || errors.Is(err, syscall.ESRCH) || errors.Is(err, errVanished)
+}
+
+func (fs *ProcFS) readProcess(pid int, bootTime, now time.Time) (Process, error) {
+	dir := filepath.Join(fs.Root, strconv.Itoa(pid))
+
+	stat, err := os.ReadFile(filepath.Join(dir, "stat"))
+	if err != nil {
+		return Process{}, err
+	}
+	if len(stat) == 0 {
+		return Process{}, errVanished
+	}
+	p, err := fs.parseStat(stat, bootTime)
+	if err != nil {
+		return Process{}, err
+	}
+
+	status, err := os.ReadFile(filepath.Join(dir, "status"))
+	if err != nil {
+		return Process{}, err
+	}
+	p.UID = parseStatusUID(status)
+	p.User = fs.LookupUser(p.UID)
+
+	cmdline, err := os.ReadFile(filepath.Join(dir, "cmdline"))
+	if err != nil {
+		return Process{}, err
+	}
+	p.Command = strings.TrimSpace(strings.ReplaceAll(string(cmdline), "\x00", " "))
+	if p.Command == "" {
+		// Threads do kernel não têm linha de comando; o ps mostra o nome entre colchetes.
+		p.Command = "[" + p.Name + "]"
+	}
+
+	if elapsed := now.Sub(p.StartTime); elapsed > 0 {
+		p.CPUPercent = 100 * p.CPUTime.Seconds() / elapsed.Seconds()
+	}
+	return p, nil
+}
+
+// parseStat interpreta o conteúdo de /proc/<pid>/stat.
+// O nome do processo fica entre parênteses e pode conter espaços, por isso
+// os demais campos são lidos a partir do último ')'.
+func (fs *ProcFS) parseStat(data []byte, bootTime time.Time) (Process, error) {
+	line := string(data)
+	open := strings.IndexByte(line, '(')
+	closing := strings.LastIndexByte(line, ')')
+	if open < 0 || closing < open {
+		return Process{}, fmt.Errorf("stat malformado: %q", line)
+	}
+
+	pid, err := strconv.Atoi(strings.TrimSpace(line[:open]))
+	if err != nil {
+		return Process{}, fmt.Errorf("pid inválido: %w", err)
+	}
+
+	// fields[0] corresponde ao campo 3 (state) da documentação de proc(5).
+	fields := strings.Fields(line[closing+1:])
+	if len(fields) < 22 {
+		return Process{}, fmt.Errorf("stat com poucos campos: %d", len(fields))
+	}
+
+	ppid, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return Process{}, fmt.Errorf("ppid inválido: %w", err)
+	}
+	utime, err := strconv.ParseInt(fields[11], 10, 64)
+	if err != nil {
+		return Process{}, fmt.Errorf("utime inválido: %w", err)
+	}
+	stime, err := strconv.ParseInt(fields[12], 10, 64)
+	if err != nil {
+		return Process{}, fmt.Errorf("stime inválido: %w", err)
+	}
+	start, err := strconv.ParseInt(fields[19], 10, 64)
+	if err != nil {
+		return Process{}, fmt.Errorf("starttime inválido: %w", err)
+	}
+	rss, err := strconv.ParseInt(fields[21], 10, 64)
+	if err != nil {
+		return Process{}, fmt.Errorf("rss inválido: %w", err)
+	}
+
+	return Process{
+		PID:       pid,
+		PPID:      ppid,
+		State:     fields[0],
+		Name:      line[open+1 : closing],
+		RSS:       uint64(max(rss, 0) * fs.PageSize),
+		CPUTime:   fs.ticks(utime + stime),
+		StartTime: bootTime.Add(fs.ticks(start)),
+	}, nil
+}
+
+func (fs *ProcFS) ticks(n int64) time.Duration {
+	return time.Duration(n) * time.Second / time.Duration(fs.ClockTicks)
+}
+
+// parseStatusUID extrai o UID real da linha "Uid:" de /proc/<pid>/status.
+func parseStatusUID(data []byte) int {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "Uid:" {
+			uid, err := strconv.Atoi(fields[1])
+			if err == nil {
+				return uid
+			}
+		}
+	}
+	return -1
+}
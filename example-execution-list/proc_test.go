@@ -0,0 +1,168 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+var testBoot = time.Unix(1700000000, 0)
+
+// fixtureFS lê testdata/proc, uma cópia reduzida de /proc:
+//
+//	1    processo comum
+//	42   comm com espaços e parênteses: "tmux: (x) srv"
+//	100  thread do kernel, sem cmdline
+//	300  sumiu entre a listagem e a leitura (só sobrou cmdline)
+//	301  sumiu entre a leitura de stat e a de status
+//	302  terminando: stat vazio (leitura curta)
+//	self diretório não numérico, ignorado
+func fixtureFS() *ProcFS {
+	return &ProcFS{
+		Root:       "testdata/proc",
+		ClockTicks: 100,
+		PageSize:   4096,
+		Now:        func() time.Time { return testBoot.Add(100 * time.Second) },
+		LookupUser: func(uid int) string { return "u" + strconv.Itoa(uid) },
+	}
+}
+
+func TestProcesses(t *testing.T) {
+	processes, err := fixtureFS().Processes()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var pids []int
+	for _, p := range processes {
+		pids = append(pids, p.PID)
+	}
+	if got, want := pids, []int{1, 42, 100}; !slices.Equal(got, want) {
+		t.Fatalf("PIDs = %v, quer %v", got, want)
+	}
+
+	init := processes[0]
+	want := Process{
+		PID:        1,
+		PPID:       0,
+		UID:        0,
+		User:       "u0",
+		State:      "S",
+		Name:       "systemd",
+		Command:    "/sbin/init splash",
+		RSS:        3000 * 4096,
+		CPUTime:    4 * time.Second,
+		CPUPercent: 4.2105263157894735, // 4s de CPU em 95s de vida
+		StartTime:  testBoot.Add(5 * time.Second),
+	}
+	if init != want {
+		t.Errorf("processo 1 =\n%+v\nquer\n%+v", init, want)
+	}
+
+	if p := processes[1]; p.Name != "tmux: (x) srv" || p.State != "R" || p.PPID != 1 || p.UID != 1000 || p.Command != "tmux new -s x" {
+		t.Errorf("processo 42 = %+v", p)
+	}
+	if p := processes[2]; p.Command != "[kworker/0:1]" || p.State != "I" {
+		t.Errorf("processo 100 = %+v", p)
+	}
+}
+
+func TestVanished(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{&os.PathError{Op: "open", Path: "/proc/9/stat", Err: syscall.ENOENT}, true},
+		{&os.PathError{Op: "read", Path: "/proc/9/cmdline", Err: syscall.ESRCH}, true},
+		{fmt.Errorf("processo 9: %w", errVanished), true},
+		{&os.PathError{Op: "open", Path: "/proc/9/stat", Err: syscall.EACCES}, false},
+		{errors.New("stat malformado"), false},
+	}
+	for _, tt := range tests {
+		if got := vanished(tt.err); got != tt.want {
+			t.Errorf("vanished(%v) = %v, quer %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestProcessesMissingRoot(t *testing.T) {
+	fs := fixtureFS()
+	fs.Root = "testdata/nada"
+	if _, err := fs.Processes(); err == nil {
+		t.Fatal("esperava erro para raiz inexistente")
+	}
+}
+
+func TestParseStat(t *testing.T) {
+	// Campos depois do nome, a partir de state; utime=30, stime=20,
+	// starttime=1000 e rss=100.
+	const rest = " S 7 1 1 0 -1 0 0 0 0 0 30 20 0 0 20 0 1 0 1000 0 100"
+
+	tests := []struct {
+		line string
+		name string
+		ppid int
+	}{
+		{"12 (bash)" + rest, "bash", 7},
+		{"12 (Web Content)" + rest, "Web Content", 7},
+		{"12 (a) b)" + rest, "a) b", 7},
+		{"12 ((sd-pam))" + rest, "(sd-pam)", 7},
+		{"12 (x) S 9 ) y)" + rest, "x) S 9 ) y", 7},
+		{"12 ()" + rest, "", 7},
+	}
+	fs := fixtureFS()
+	for _, tt := range tests {
+		p, err := fs.parseStat([]byte(tt.line+"\n"), testBoot)
+		if err != nil {
+			t.Errorf("parseStat(%q): %v", tt.line, err)
+			continue
+		}
+		if p.PID != 12 || p.Name != tt.name || p.PPID != tt.ppid || p.State != "S" {
+			t.Errorf("parseStat(%q) = pid %d nome %q ppid %d estado %q", tt.line, p.PID, p.Name, p.PPID, p.State)
+		}
+		if p.CPUTime != 500*time.Millisecond || p.RSS != 100*4096 || !p.StartTime.Equal(testBoot.Add(10*time.Second)) {
+			t.Errorf("parseStat(%q) = cpu %v rss %d início %v", tt.line, p.CPUTime, p.RSS, p.StartTime)
+		}
+	}
+}
+
+func TestParseStatMalformed(t *testing.T) {
+	tests := []string{
+		"",
+		"12 bash S 1",
+		"12 )bash( S 1 1 1 0 -1 0 0 0 0 0 30 20 0 0 20 0 1 0 1000 0 100",
+		"x (bash) S 1 1 1 0 -1 0 0 0 0 0 30 20 0 0 20 0 1 0 1000 0 100",
+		"12 (bash) S 1 1 1",
+		"12 (bash) S p 1 1 0 -1 0 0 0 0 0 30 20 0 0 20 0 1 0 1000 0 100",
+		"12 (bash) S 1 1 1 0 -1 0 0 0 0 0 u 20 0 0 20 0 1 0 1000 0 100",
+	}
+	fs := fixtureFS()
+	for _, line := range tests {
+		if p, err := fs.parseStat([]byte(line), testBoot); err == nil {
+			t.Errorf("parseStat(%q) = %+v, esperava erro", line, p)
+		}
+	}
+}
+
+func TestParseStatusUID(t *testing.T) {
+	tests := []struct {
+		status string
+		uid    int
+	}{
+		{"Name:\tbash\nUid:\t1000\t1000\t1000\t1000\n", 1000},
+		{"Uid:\t0\t0\t0\t0", 0},
+		{"Name:\tbash\n", -1},
+		{"Uid:\tx\n", -1},
+	}
+	for _, tt := range tests {
+		if got := parseStatusUID([]byte(tt.status)); got != tt.uid {
+			t.Errorf("parseStatusUID(%q) = %d, quer %d", strings.SplitN(tt.status, "\n", 2)[0], got, tt.uid)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"regexp"
+	"strings"
+	"syscall"
+	"time"
+	"unicode"
+)
+
+// Server expõe a tabela de processos via HTTP.
+// Patterns define quais processos, pelo nome, aparecem em /metrics.
+type Server struct {
+	FS       *ProcFS
+	Patterns []*regexp.Regexp
+}
+
+// Handler retorna o roteador com /processes e /metrics.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/processes", s.handleProcesses)
+	mux.HandleFunc("/metrics", s.handleMetrics)
+	return mux
+}
+
+// handleProcesses responde com os processos em JSON, aceitando os mesmos
+// filtros e ordenação da linha de comando via query string.
+func (s *Server) handleProcesses(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	filter := Filter{User: q.Get("user"), Name: q.Get("name")}
+	if match := q.Get("match"); match != "" {
+		re, err := regexp.Compile(match)
+		if err != nil {
+			http.Error(w, "expressão regular inválida: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		filter.Match = re
+	}
+	sortKey := q.Get("sort")
+	if sortKey == "" {
+		sortKey = "pid"
+	}
+
+	processes, err := s.FS.Processes()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	processes = filter.Apply(processes)
+	if err := SortProcesses(processes, sortKey); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if processes == nil {
+		processes = []Process{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(processes)
+}
+
+// handleMetrics responde no formato texto do Prometheus.
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	processes, err := s.FS.Processes()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	WriteMetrics(w, processes, s.Patterns)
+}
+
+// WriteMetrics escreve as métricas dos processos cujo nome casa com algum padrão.
+// Um processo que casa com mais de um padrão aparece uma vez para cada padrão.
+func WriteMetrics(w io.Writer, processes []Process, patterns []*regexp.Regexp) {
+	type sample struct {
+		pattern string
+		p       Process
+	}
+	var samples []sample
+	for _, re := range patterns {
+		for _, p := range processes {
+			if re.MatchString(p.Name) {
+				samples = append(samples, sample{re.String(), p})
+			}
+		}
+	}
+
+	fmt.Fprintln(w, "# HELP execlist_processes Quantidade de processos que casam com o padrão.")
+	fmt.Fprintln(w, "# TYPE execlist_processes gauge")
+	for _, re := range patterns {
+		count := 0
+		for _, s := range samples {
+			if s.pattern == re.String() {
+				count++
+			}
+		}
+		fmt.Fprintf(w, "execlist_processes{pattern=\"%s\"} %d\n", escapeLabel(re.String()), count)
+	}
+
+	metrics := []struct {
+		name, help, kind string
+		value            func(Process) float64
+	}{
+		{"execlist_process_cpu_seconds_total", "Tempo de CPU (usuário + sistema) consumido pelo processo.", "counter",
+			func(p Process) float64 { return p.CPUTime.Seconds() }},
+		{"execlist_process_cpu_percent", "Uso médio de CPU desde o início do processo, como no ps.", "gauge",
+			func(p Process) float64 { return p.CPUPercent }},
+		{"execlist_process_resident_memory_bytes", "Memória residente (RSS) do processo.", "gauge",
+			func(p Process) float64 { return float64(p.RSS) }},
+		{"execlist_process_start_time_seconds", "Início do processo em segundos desde a época Unix.", "gauge",
+			func(p Process) float64 { return float64(p.StartTime.Unix()) }},
+	}
+	for _, m := range metrics {
+		fmt.Fprintf(w, "# HELP %s %s\n", m.name, m.help)
+		fmt.Fprintf(w, "# TYPE %s %s\n", m.name, m.kind)
+		for _, s := range samples {
+			fmt.Fprintf(w, "%s{pattern=\"%s\",pid=\"%d\",name=\"%s\",user=\"%s\"} %g\n", m.name,
+				escapeLabel(s.pattern), s.p.PID, escapeLabel(s.p.Name), escapeLabel(s.p.User), m.value(s.p))
+		}
+	}
+}
+
+// escapeLabel prepara o valor de um rótulo para o formato texto do
+// Prometheus, que só aceita as sequências \\, \" e \n: bytes que não são
+// UTF-8 válido viram U+FFFD e os demais caracteres de controle são removidos.
+func escapeLabel(v string) string {
+	var b strings.Builder
+	for _, r := range strings.ToValidUTF8(v, "\uFFFD") {
+		switch {
+		case r == '\\':
+			b.WriteString(`\\`)
+		case r == '"':
+			b.WriteString(`\"`)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case unicode.IsControl(r):
+			// removido
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// patternList acumula os valores de uma flag -pattern repetida.
+type patternList []*regexp.Regexp
+
+func (l *patternList) String() string {
+	names := make([]string, len(*l))
+	for i, re := range *l {
+		names[i] = re.String()
+	}
+	return strings.Join(names, ",")
+}
+
+func (l *patternList) Set(v string) error {
+	re, err := regexp.Compile(v)
+	if err != nil {
+		return err
+	}
+	*l = append(*l, re)
+	return nil
+}
+
+// runServe implementa o subcomando `serve`.
+func runServe(args []string) error {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := flags.String("addr", ":9256", "endereço HTTP")
+	procRoot := flags.String("proc", "/proc", "raiz do sistema de arquivos /proc")
+	var patterns patternList
+	flags.Var(&patterns, "pattern", "expressão regular aplicada ao nome dos processos exportados em /metrics (pode repetir)")
+	flags.Parse(args)
+
+	if len(patterns) == 0 {
+		return errors.New("informe ao menos um -pattern para /metrics")
+	}
+
+	server := &http.Server{
+		Addr:              *addr,
+		Handler:           (&Server{FS: NewProcFS(*procRoot), Patterns: patterns}).Handler(),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	// ListenAndServe retorna assim que Shutdown começa; shutdownDone só
+	// fecha depois que as conexões abertas terminam (ou o prazo acaba).
+	shutdownDone := make(chan error, 1)
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		shutdownDone <- server.Shutdown(shutdownCtx)
+	}()
+
+	log.Printf("Servidor iniciado em %s (/processes, /metrics)", *addr)
+	if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return <-shutdownDone
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestEscapeLabel(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"bash", "bash"},
+		{`a\b`, `a\\b`},
+		{`diz "oi"`, `diz \"oi\"`},
+		{"duas\nlinhas", `duas\nlinhas`},
+		{"tab\there", "tabhere"},
+		{"del\x7f", "del"},
+		{"inválido\xff", "inválido\uFFFD"},
+		{"nbsp\u0085", "nbsp"},
+		{"ação ✓", "ação ✓"},
+	}
+	for _, tt := range tests {
+		if got := escapeLabel(tt.in); got != tt.want {
+			t.Errorf("escapeLabel(%q) = %q, quer %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestWriteMetrics(t *testing.T) {
+	processes := []Process{
+		{PID: 7, Name: "a\"b\x7f", User: "zé", RSS: 2048},
+		{PID: 8, Name: "outro"},
+	}
+	var buf bytes.Buffer
+	WriteMetrics(&buf, processes, []*regexp.Regexp{regexp.MustCompile(`^a`)})
+	out := buf.String()
+
+	for _, want := range []string{
+		`execlist_processes{pattern="^a"} 1`,
+		`execlist_process_resident_memory_bytes{pattern="^a",pid="7",name="a\"b",user="zé"} 2048`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("saída sem %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, `\x`) || strings.Contains(out, `\u`) {
+		t.Errorf("saída com escape não suportado pelo Prometheus:\n%s", out)
+	}
+}
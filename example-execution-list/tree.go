@@ -0,0 +1,187 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Node representa um processo na árvore montada a partir das relações de PPID.
+// Count é maior que 1 quando irmãos idênticos foram agrupados.
+type Node struct {
+	Process
+	Count    int     `json:"count"`
+	Children []*Node `json:"children,omitempty"`
+}
+
+// BuildTree monta a árvore de processos. Processos cujo pai não está na
+// lista (por exemplo PID 1 e kthreadd) viram raízes.
+func BuildTree(processes []Process) []*Node {
+	nodes := make(map[int]*Node, len(processes))
+	for _, p := range processes {
+		nodes[p.PID] = &Node{Process: p, Count: 1}
+	}
+
+	var roots []*Node
+	for _, p := range processes {
+		node := nodes[p.PID]
+		parent, ok := nodes[p.PPID]
+		if !ok || p.PPID == p.PID {
+			roots = append(roots, node)
+			continue
+		}
+		parent.Children = append(parent.Children, node)
+	}
+
+	// Um ciclo de PPIDs, possível quando um PID é reaproveitado durante a
+	// leitura, não teria raiz e sumiria da árvore: o menor PID de cada ciclo
+	// vira raiz.
+	reached := make(map[*Node]bool, len(nodes))
+	var reach func(n *Node)
+	reach = func(n *Node) {
+		if reached[n] {
+			return
+		}
+		reached[n] = true
+		for _, c := range n.Children {
+			reach(c)
+		}
+	}
+	for _, r := range roots {
+		reach(r)
+	}
+	pids := make([]int, 0, len(nodes))
+	for pid := range nodes {
+		pids = append(pids, pid)
+	}
+	sort.Ints(pids)
+	for _, pid := range pids {
+		node := nodes[pid]
+		if reached[node] {
+			continue
+		}
+		parent := nodes[node.PPID]
+		parent.Children = slices.DeleteFunc(parent.Children, func(c *Node) bool { return c == node })
+		roots = append(roots, node)
+		reach(node)
+	}
+
+	sortNodes(roots)
+	return roots
+}
+
+func sortNodes(nodes []*Node) {
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].PID < nodes[j].PID })
+	for _, n := range nodes {
+		sortNodes(n.Children)
+	}
+}
+
+// FindRoots retorna as subárvores cuja raiz tem o PID ou o nome informado.
+// Quando um processo encontrado descende de outro também encontrado, apenas
+// o ancestral é retornado.
+func FindRoots(roots []*Node, selector string) []*Node {
+	pid, err := strconv.Atoi(selector)
+	isPID := err == nil
+
+	var found []*Node
+	var walk func(nodes []*Node)
+	walk = func(nodes []*Node) {
+		for _, n := range nodes {
+			if (isPID && n.PID == pid) || (!isPID && n.Name == selector) {
+				found = append(found, n)
+				continue
+			}
+			walk(n.Children)
+		}
+	}
+	walk(roots)
+	return found
+}
+
+// Prune mantém apenas os nós que atendem a keep ou que têm algum descendente
+// que atende, preservando o caminho até a raiz.
+func Prune(nodes []*Node, keep func(Process) bool) []*Node {
+	var kept []*Node
+	for _, n := range nodes {
+		n.Children = Prune(n.Children, keep)
+		if keep(n.Process) || len(n.Children) > 0 {
+			kept = append(kept, n)
+		}
+	}
+	return kept
+}
+
+// Collapse agrupa irmãos com o mesmo nome e subárvores idênticas, como o pstree faz.
+func Collapse(nodes []*Node) []*Node {
+	var collapsed []*Node
+	seen := make(map[string]*Node)
+	for _, n := range nodes {
+		n.Children = Collapse(n.Children)
+
+		sig := signature(n)
+		if first, ok := seen[sig]; ok {
+			first.Count += n.Count
+			continue
+		}
+		seen[sig] = n
+		collapsed = append(collapsed, n)
+	}
+	return collapsed
+}
+
+// signature descreve o formato da subárvore ignorando PIDs.
+func signature(n *Node) string {
+	var b strings.Builder
+	b.WriteString(n.Name)
+	if len(n.Children) > 0 {
+		b.WriteByte('(')
+		for i, c := range n.Children {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			fmt.Fprintf(&b, "%d*%s", c.Count, signature(c))
+		}
+		b.WriteByte(')')
+	}
+	return b.String()
+}
+
+// WriteTree escreve a árvore em texto indentado no estilo do pstree.
+func WriteTree(w io.Writer, roots []*Node) error {
+	for _, root := range roots {
+		if _, err := fmt.Fprintln(w, nodeLabel(root)); err != nil {
+			return err
+		}
+		if err := writeChildren(w, root.Children, ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeChildren(w io.Writer, children []*Node, prefix string) error {
+	for i, c := range children {
+		branch, next := "├─ ", "│  "
+		if i == len(children)-1 {
+			branch, next = "└─ ", "   "
+		}
+		if _, err := fmt.Fprintln(w, prefix+branch+nodeLabel(c)); err != nil {
+			return err
+		}
+		if err := writeChildren(w, c.Children, prefix+next); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func nodeLabel(n *Node) string {
+	if n.Count > 1 {
+		return fmt.Sprintf("%d*[%s]", n.Count, n.Name)
+	}
+	return fmt.Sprintf("%s(%d)", n.Name, n.PID)
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// treeProc cria um processo com PID, PPID e nome.
+func treeProc(pid, ppid int, name string) Process {
+	return Process{PID: pid, PPID: ppid, Name: name}
+}
+
+// shape descreve a árvore como "1(2,3(4))"; grupos aparecem como "2*nome".
+func shape(nodes []*Node) string {
+	var parts []string
+	for _, n := range nodes {
+		s := fmt.Sprint(n.PID)
+		if n.Count > 1 {
+			s = fmt.Sprintf("%d*%s", n.Count, n.Name)
+		}
+		if len(n.Children) > 0 {
+			s += "(" + shape(n.Children) + ")"
+		}
+		parts = append(parts, s)
+	}
+	return strings.Join(parts, ",")
+}
+
+// sample é uma tabela pequena: init com sshd e dois bash, cada um com um
+// vim, e kthreadd, que tem PPID 0.
+var sample = []Process{
+	treeProc(30, 20, "bash"),
+	treeProc(1, 0, "systemd"),
+	treeProc(2, 0, "kthreadd"),
+	treeProc(20, 1, "sshd"),
+	treeProc(31, 30, "vim"),
+	treeProc(40, 20, "bash"),
+	treeProc(41, 40, "vim"),
+	treeProc(5, 2, "kworker"),
+}
+
+func TestBuildTree(t *testing.T) {
+	tests := []struct {
+		name      string
+		processes []Process
+		want      string
+	}{
+		{"vazia", nil, ""},
+		{"árvore", sample, "1(20(30(31),40(41))),2(5)"},
+		// O pai não está na lista (terminou ou foi filtrado): vira raiz
+		{"órfãos", []Process{treeProc(10, 1, "a"), treeProc(11, 10, "b"), treeProc(12, 99, "c")}, "10(11),12"},
+		{"pai de si mesmo", []Process{treeProc(7, 7, "a"), treeProc(8, 7, "b")}, "7(8)"},
+		// Sem raiz, os ciclos sumiriam: o menor PID de cada um vira raiz
+		{"ciclo de dois", []Process{treeProc(11, 10, "b"), treeProc(10, 11, "a"), treeProc(12, 11, "c")}, "10(11(12))"},
+		{"ciclo de três", []Process{treeProc(3, 5, "c"), treeProc(4, 3, "a"), treeProc(5, 4, "b"), treeProc(1, 0, "init")}, "1,3(4(5))"},
+		{"dois ciclos", []Process{treeProc(8, 9, "a"), treeProc(9, 8, "b"), treeProc(6, 7, "c"), treeProc(7, 6, "d")}, "6(7),8(9)"},
+	}
+	for _, tt := range tests {
+		if got := shape(BuildTree(tt.processes)); got != tt.want {
+			t.Errorf("%s: BuildTree = %s, quer %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFindRoots(t *testing.T) {
+	tests := []struct {
+		selector string
+		want     string
+	}{
+		{"20", "20(30(31),40(41))"},
+		{"41", "41"},
+		{"kthreadd", "2(5)"},
+		{"vim", "31,41"},
+		// bash 30 e 40 são irmãos; se um descendesse do outro, só o
+		// ancestral seria retornado
+		{"bash", "30(31),40(41)"},
+		{"sshd", "20(30(31),40(41))"},
+		{"999", ""},
+		{"nada", ""},
+	}
+	for _, tt := range tests {
+		if got := shape(FindRoots(BuildTree(sample), tt.selector)); got != tt.want {
+			t.Errorf("FindRoots(%q) = %s, quer %s", tt.selector, got, tt.want)
+		}
+	}
+
+	// Um processo encontrado dentro de outro encontrado não se repete
+	nested := []Process{treeProc(1, 0, "sh"), treeProc(2, 1, "sh"), treeProc(3, 2, "sh")}
+	if got := shape(FindRoots(BuildTree(nested), "sh")); got != "1(2(3))" {
+		t.Errorf("FindRoots(sh) aninhado = %s", got)
+	}
+}
+
+func TestPrune(t *testing.T) {
+	tests := []struct {
+		name string
+		keep func(Process) bool
+		want string
+	}{
+		{"tudo", func(Process) bool { return true }, "1(20(30(31),40(41))),2(5)"},
+		{"nada", func(Process) bool { return false }, ""},
+		// O caminho até a raiz é mantido mesmo sem passar no filtro
+		{"folha", func(p Process) bool { return p.PID == 41 }, "1(20(40(41)))"},
+		{"nome", func(p Process) bool { return p.Name == "vim" || p.Name == "kworker" }, "1(20(30(31),40(41))),2(5)"},
+		{"interno", func(p Process) bool { return p.Name == "sshd" }, "1(20)"},
+	}
+	for _, tt := range tests {
+		if got := shape(Prune(BuildTree(sample), tt.keep)); got != tt.want {
+			t.Errorf("%s: Prune = %s, quer %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCollapse(t *testing.T) {
+	tests := []struct {
+		name      string
+		processes []Process
+		want      string
+	}{
+		{"subárvores iguais", sample, "1(20(2*bash(31))),2(5)"},
+		{"folhas iguais", []Process{treeProc(1, 0, "nginx"), treeProc(2, 1, "worker"), treeProc(3, 1, "worker"), treeProc(4, 1, "worker")}, "1(3*worker)"},
+		// Mesmo nome, filhos diferentes: não agrupa
+		{"filhos diferentes", []Process{treeProc(1, 0, "sh"), treeProc(2, 1, "bash"), treeProc(3, 2, "vim"), treeProc(4, 1, "bash"), treeProc(5, 4, "top")}, "1(2(3),4(5))"},
+		{"sem irmãos", []Process{treeProc(1, 0, "a"), treeProc(2, 1, "a")}, "1(2)"},
+	}
+	for _, tt := range tests {
+		if got := shape(Collapse(BuildTree(tt.processes))); got != tt.want {
+			t.Errorf("%s: Collapse = %s, quer %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestWriteTree(t *testing.T) {
+	var b strings.Builder
+	if err := WriteTree(&b, Collapse(BuildTree(sample))); err != nil {
+		t.Fatal(err)
+	}
+	want := `systemd(1)
+└─ sshd(20)
+   └─ 2*[bash]
+      └─ vim(31)
+kthreadd(2)
+└─ kworker(5)
+`
+	if b.String() != want {
+		t.Errorf("WriteTree =\n%s\nquer\n%s", b.String(), want)
+	}
+}
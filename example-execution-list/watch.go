@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// EventKind identifica o tipo de mudança entre duas amostras.
+type EventKind string
+
+const (
+	EventStarted   EventKind = "started"
+	EventExited    EventKind = "exited"
+	EventRSSGrowth EventKind = "rss_growth"
+)
+
+// Event representa uma mudança detectada entre duas amostras da tabela de processos.
+// PreviousRSS só é preenchido em eventos de crescimento de memória.
+type Event struct {
+	Kind        EventKind `json:"kind"`
+	Time        time.Time `json:"time"`
+	Process     Process   `json:"process"`
+	PreviousRSS uint64    `json:"previous_rss,omitempty"`
+}
+
+// processKey identifica um processo de forma única mesmo com reaproveitamento de PID.
+type processKey struct {
+	PID   int
+	Start time.Time
+}
+
+func keyOf(p Process) processKey {
+	return processKey{PID: p.PID, Start: p.StartTime}
+}
+
+// Watcher compara amostras sucessivas da tabela de processos.
+// O RSS de referência de cada processo só é atualizado quando um crescimento
+// é reportado, assim vazamentos lentos acumulam até passar do limite.
+type Watcher struct {
+	RSSGrowth uint64
+
+	seen     map[processKey]Process
+	baseline map[processKey]uint64
+}
+
+// NewWatcher cria um Watcher que reporta crescimentos de RSS acima de rssGrowth bytes.
+// Com rssGrowth zero, crescimentos de memória não são reportados.
+func NewWatcher(rssGrowth uint64) *Watcher {
+	return &Watcher{RSSGrowth: rssGrowth}
+}
+
+// Step registra uma nova amostra e retorna os eventos em relação à anterior.
+// A primeira amostra só define a referência e não gera eventos.
+func (w *Watcher) Step(now time.Time, processes []Process) []Event {
+	current := make(map[processKey]Process, len(processes))
+	for _, p := range processes {
+		current[keyOf(p)] = p
+	}
+
+	if w.seen == nil {
+		w.seen = current
+		w.baseline = make(map[processKey]uint64, len(current))
+		for k, p := range current {
+			w.baseline[k] = p.RSS
+		}
+		return nil
+	}
+
+	var events []Event
+	for k, p := range current {
+		if _, ok := w.seen[k]; !ok {
+			events = append(events, Event{Kind: EventStarted, Time: now, Process: p})
+			w.baseline[k] = p.RSS
+			continue
+		}
+		if base := w.baseline[k]; w.RSSGrowth > 0 && p.RSS > base && p.RSS-base >= w.RSSGrowth {
+			events = append(events, Event{Kind: EventRSSGrowth, Time: now, Process: p, PreviousRSS: base})
+			w.baseline[k] = p.RSS
+		}
+	}
+	for k, p := range w.seen {
+		if _, ok := current[k]; !ok {
+			events = append(events, Event{Kind: EventExited, Time: now, Process: p})
+			delete(w.baseline, k)
+		}
+	}
+	w.seen = current
+
+	sort.Slice(events, func(i, j int) bool {
+		if events[i].Process.PID != events[j].Process.PID {
+			return events[i].Process.PID < events[j].Process.PID
+		}
+		return events[i].Kind < events[j].Kind
+	})
+	return events
+}
+
+// Watch amostra a tabela de processos a cada intervalo até o contexto ser
+// cancelado, chamando report com os eventos de cada amostra.
+func Watch(ctx context.Context, fs *ProcFS, filter Filter, interval time.Duration, w *Watcher, report func([]Event) error) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		processes, err := fs.Processes()
+		if err != nil {
+			return err
+		}
+		if err := report(w.Step(fs.Now(), filter.Apply(processes))); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// WriteEvents escreve os eventos em texto, um por linha.
+func WriteEvents(out io.Writer, events []Event) error {
+	for _, e := range events {
+		p := e.Process
+		var err error
+		switch e.Kind {
+		case EventStarted:
+			_, err = fmt.Fprintf(out, "%s INICIADO  pid=%d user=%s %s\n", e.Time.Format("15:04:05"), p.PID, p.User, p.Command)
+		case EventExited:
+			_, err = fmt.Fprintf(out, "%s ENCERRADO pid=%d user=%s %s\n", e.Time.Format("15:04:05"), p.PID, p.User, p.Command)
+		case EventRSSGrowth:
+			_, err = fmt.Fprintf(out, "%s RSS       pid=%d %s: %d KB -> %d KB (+%d KB)\n", e.Time.Format("15:04:05"),
+				p.PID, p.Name, e.PreviousRSS/1024, p.RSS/1024, (p.RSS-e.PreviousRSS)/1024)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+)
+
+// proc cria um processo iniciado start depois do boot, com rssKB de memória.
+func proc(pid int, start time.Duration, rssKB uint64) Process {
+	return Process{PID: pid, Name: fmt.Sprint("p", pid), RSS: rssKB * 1024, StartTime: testBoot.Add(start)}
+}
+
+// kinds resume os eventos como "pid tipo", na ordem retornada.
+func kinds(events []Event) []string {
+	var out []string
+	for _, e := range events {
+		out = append(out, fmt.Sprint(e.Process.PID, " ", e.Kind))
+	}
+	return out
+}
+
+func TestWatcherStep(t *testing.T) {
+	tests := []struct {
+		name      string
+		rssGrowth uint64 // KB
+		samples   [][]Process
+		want      [][]string // eventos de cada amostra
+	}{
+		{
+			name:    "primeira amostra só define a referência",
+			samples: [][]Process{{proc(1, 0, 100), proc(2, 0, 100)}},
+			want:    [][]string{nil},
+		},
+		{
+			name: "início e fim",
+			samples: [][]Process{
+				{proc(1, 0, 100), proc(2, 0, 100)},
+				{proc(2, 0, 100), proc(3, time.Second, 100)},
+				{proc(2, 0, 100), proc(3, time.Second, 100)},
+				{},
+			},
+			want: [][]string{nil, {"1 exited", "3 started"}, nil, {"2 exited", "3 exited"}},
+		},
+		{
+			// Mesmo PID com outro horário de início é outro processo
+			name: "PID reaproveitado",
+			samples: [][]Process{
+				{proc(7, 0, 100)},
+				{proc(7, time.Minute, 100)},
+			},
+			want: [][]string{nil, {"7 exited", "7 started"}},
+		},
+		{
+			// A referência só muda quando o alerta sai: um vazamento lento
+			// acumula até passar do limite
+			name:      "crescimento de RSS",
+			rssGrowth: 10,
+			samples: [][]Process{
+				{proc(1, 0, 100)},
+				{proc(1, 0, 105)},
+				{proc(1, 0, 109)},
+				{proc(1, 0, 110)},
+				{proc(1, 0, 115)},
+				{proc(1, 0, 50)},
+				{proc(1, 0, 121)},
+			},
+			want: [][]string{nil, nil, nil, {"1 rss_growth"}, nil, nil, {"1 rss_growth"}},
+		},
+		{
+			name:      "processo novo tem referência própria",
+			rssGrowth: 10,
+			samples: [][]Process{
+				{},
+				{proc(2, 0, 500)},
+				{proc(2, 0, 505)},
+				{proc(2, 0, 515)},
+			},
+			want: [][]string{nil, {"2 started"}, nil, {"2 rss_growth"}},
+		},
+		{
+			name:      "limite zero desativa o alerta de memória",
+			rssGrowth: 0,
+			samples: [][]Process{
+				{proc(1, 0, 100)},
+				{proc(1, 0, 100000)},
+			},
+			want: [][]string{nil, nil},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := NewWatcher(tt.rssGrowth * 1024)
+			for i, sample := range tt.samples {
+				got := kinds(w.Step(testBoot.Add(time.Duration(i)*time.Minute), sample))
+				if !slices.Equal(got, tt.want[i]) {
+					t.Errorf("amostra %d: eventos = %q, quer %q", i, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestWatcherStepEvent(t *testing.T) {
+	w := NewWatcher(10 * 1024)
+	w.Step(testBoot, []Process{proc(1, 0, 100)})
+
+	now := testBoot.Add(time.Minute)
+	events := w.Step(now, []Process{proc(1, 0, 130)})
+	want := Event{Kind: EventRSSGrowth, Time: now, Process: proc(1, 0, 130), PreviousRSS: 100 * 1024}
+	if len(events) != 1 || events[0] != want {
+		t.Errorf("eventos = %+v, quer %+v", events, want)
+	}
+}
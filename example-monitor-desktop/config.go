@@ -0,0 +1,71 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config reúne os parâmetros do monitor. Pode ser lida de um arquivo YAML
+// e os valores podem ser sobrescritos por flags.
+type Config struct {
+	Source            string        `yaml:"source"`
+	Journal           string        `yaml:"journal"`
+	PollInterval      time.Duration `yaml:"poll_interval"`
+	IdleThreshold     time.Duration `yaml:"idle_threshold"`
+	LongAwayThreshold time.Duration `yaml:"long_away_threshold"`
+	Hooks             []Hook        `yaml:"hooks"`
+}
+
+// DefaultConfig retorna a configuração usada quando nada é informado.
+func DefaultConfig() Config {
+	return Config{
+		Source:            "mouse",
+		Journal:           defaultJournal,
+		PollInterval:      5 * time.Second,
+		IdleThreshold:     7 * time.Second,
+		LongAwayThreshold: 15 * time.Minute,
+	}
+}
+
+// LoadConfig lê o arquivo YAML em path sobre os valores de DefaultConfig,
+// então só precisam aparecer nele as opções alteradas. Uma opção com nome
+// desconhecido é erro, assim como valores que não passam em Validate.
+func LoadConfig(path string) (Config, error) {
+	cfg := DefaultConfig()
+
+	f, err := os.Open(path)
+	if err != nil {
+		return cfg, err
+	}
+	defer f.Close()
+
+	dec := yaml.NewDecoder(f)
+	dec.KnownFields(true)
+	if err := dec.Decode(&cfg); err != nil {
+		return cfg, fmt.Errorf("%s: %w", path, err)
+	}
+	return cfg, cfg.Validate()
+}
+
+// Validate verifica se os intervalos e ganchos fazem sentido.
+func (c Config) Validate() error {
+	if c.PollInterval <= 0 {
+		return errors.New("poll_interval deve ser maior que zero")
+	}
+	if c.IdleThreshold < c.PollInterval {
+		return errors.New("idle_threshold deve ser maior ou igual a poll_interval")
+	}
+	if c.LongAwayThreshold < 0 {
+		return errors.New("long_away_threshold não pode ser negativo")
+	}
+	for i, h := range c.Hooks {
+		if err := h.Validate(); err != nil {
+			return fmt.Errorf("hooks[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
@@ -1,8 +1,11 @@
-module main
+module example-monitor-desktop
 
 go 1.22.6
 
-require github.com/go-vgo/robotgo v0.110.5
+require (
+	github.com/go-vgo/robotgo v0.110.5
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require (
 	github.com/dblohm7/wingoes v0.0.0-20240820181039-f2b84150679e // indirect
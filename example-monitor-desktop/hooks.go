@@ -0,0 +1,156 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Eventos que disparam ganchos.
+const (
+	EventIdle     = "idle"      // o usuário ficou ausente
+	EventActive   = "active"    // o usuário voltou
+	EventLongAway = "long_away" // o usuário está ausente há mais que long_away_threshold
+)
+
+// Hook executa um comando e/ou envia um webhook quando Event acontece.
+type Hook struct {
+	Event   string `yaml:"event"`
+	Command string `yaml:"command"`
+	Webhook string `yaml:"webhook"`
+}
+
+// Validate verifica o evento e se há alguma ação configurada.
+func (h Hook) Validate() error {
+	switch h.Event {
+	case EventIdle, EventActive, EventLongAway:
+	default:
+		return fmt.Errorf("evento desconhecido %q (use %s, %s ou %s)", h.Event, EventIdle, EventActive, EventLongAway)
+	}
+	if h.Command == "" && h.Webhook == "" {
+		return errors.New("informe command ou webhook")
+	}
+	return nil
+}
+
+// HookPayload é o corpo JSON enviado aos webhooks. Os mesmos dados são
+// passados aos comandos pelas variáveis MONITOR_EVENT, MONITOR_STATE,
+// MONITOR_SINCE e MONITOR_AWAY_SECONDS.
+type HookPayload struct {
+	Event       string    `json:"event"`
+	State       State     `json:"state"`
+	Since       time.Time `json:"since"`
+	AwaySeconds int64     `json:"away_seconds,omitempty"`
+}
+
+// HookRunner dispara os ganchos configurados.
+type HookRunner struct {
+	Hooks   []Hook
+	Timeout time.Duration
+	Client  *http.Client
+
+	running sync.WaitGroup
+}
+
+// NewHookRunner cria um HookRunner com tempo limite de 10 segundos por ação.
+func NewHookRunner(hooks []Hook) *HookRunner {
+	return &HookRunner{Hooks: hooks, Timeout: 10 * time.Second, Client: http.DefaultClient}
+}
+
+// Fire executa em segundo plano todos os ganchos do evento, para não atrasar
+// as verificações de atividade. Falhas são apenas registradas no log.
+func (r *HookRunner) Fire(payload HookPayload) {
+	for _, h := range r.Hooks {
+		if h.Event != payload.Event {
+			continue
+		}
+		r.running.Add(1)
+		go func(h Hook) {
+			defer r.running.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), r.Timeout)
+			defer cancel()
+			if err := r.run(ctx, h, payload); err != nil {
+				log.Printf("Erro no gancho %s: %v", h.Event, err)
+			}
+		}(h)
+	}
+}
+
+// Wait espera os ganchos em andamento terminarem ou ctx ser cancelado, o que
+// vier primeiro. No segundo caso retorna o erro de ctx.
+func (r *HookRunner) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		r.running.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (r *HookRunner) run(ctx context.Context, h Hook, payload HookPayload) error {
+	var errs []error
+	if h.Command != "" {
+		errs = append(errs, runCommand(ctx, h.Command, payload))
+	}
+	if h.Webhook != "" {
+		errs = append(errs, r.postWebhook(ctx, h.Webhook, payload))
+	}
+	return errors.Join(errs...)
+}
+
+// runCommand executa o comando pelo shell do sistema.
+func runCommand(ctx context.Context, command string, payload HookPayload) error {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	cmd.Env = append(os.Environ(),
+		"MONITOR_EVENT="+payload.Event,
+		"MONITOR_STATE="+payload.State.String(),
+		"MONITOR_SINCE="+payload.Since.Format(time.RFC3339),
+		"MONITOR_AWAY_SECONDS="+strconv.FormatInt(payload.AwaySeconds, 10),
+	)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// postWebhook envia o payload em JSON para a URL.
+func (r *HookRunner) postWebhook(ctx context.Context, url string, payload HookPayload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := r.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook %s respondeu %s", url, resp.Status)
+	}
+	return nil
+}
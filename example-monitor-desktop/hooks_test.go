@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHookRunnerWait(t *testing.T) {
+	release := make(chan struct{})
+	got := make(chan HookPayload, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var p HookPayload
+		json.NewDecoder(r.Body).Decode(&p)
+		<-release
+		got <- p
+	}))
+	defer srv.Close()
+
+	runner := NewHookRunner([]Hook{
+		{Event: EventActive, Webhook: srv.URL},
+		{Event: EventIdle, Webhook: "http://127.0.0.1:1/nunca"},
+	})
+	since := time.Date(2024, 10, 7, 9, 0, 0, 0, time.UTC)
+	runner.Fire(HookPayload{Event: EventActive, State: StateActive, Since: since})
+
+	// Enquanto o webhook não responde, Wait desiste quando o contexto expira
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := runner.Wait(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("Wait() = %v, quer DeadlineExceeded", err)
+	}
+
+	close(release)
+	if err := runner.Wait(context.Background()); err != nil {
+		t.Fatalf("Wait() = %v", err)
+	}
+	select {
+	case p := <-got:
+		if p.Event != EventActive || p.State != StateActive || !p.Since.Equal(since) {
+			t.Errorf("payload = %+v", p)
+		}
+	default:
+		t.Fatal("Wait retornou antes de o webhook terminar")
+	}
+}
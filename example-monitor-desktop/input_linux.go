@@ -0,0 +1,75 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+)
+
+// InputSource detecta atividade de teclado e mouse lendo os dispositivos
+// /dev/input/event*. O usuário precisa ter permissão de leitura neles
+// (normalmente fazendo parte do grupo "input").
+type InputSource struct {
+	Now func() time.Time
+
+	last  atomic.Int64
+	files []*os.File
+}
+
+// NewInputSource abre todos os dispositivos de entrada que casam com pattern
+// e começa a ler seus eventos em segundo plano.
+func NewInputSource(pattern string, now func() time.Time) (*InputSource, error) {
+	paths, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &InputSource{Now: now}
+	s.last.Store(now().UnixNano())
+	var openErr error
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			openErr = err
+			continue
+		}
+		s.files = append(s.files, f)
+		go s.read(f)
+	}
+
+	if len(s.files) == 0 {
+		if openErr != nil {
+			return nil, openErr
+		}
+		return nil, errors.New("nenhum dispositivo de entrada encontrado em " + pattern)
+	}
+	return s, nil
+}
+
+// read marca atividade a cada leitura. O conteúdo dos eventos (struct
+// input_event) não importa: qualquer tecla ou movimento conta como atividade.
+func (s *InputSource) read(f *os.File) {
+	buf := make([]byte, 24*64)
+	for {
+		if _, err := f.Read(buf); err != nil {
+			return
+		}
+		s.last.Store(s.Now().UnixNano())
+	}
+}
+
+// LastActivity retorna o momento do último evento lido em qualquer dispositivo.
+func (s *InputSource) LastActivity() (time.Time, error) {
+	return time.Unix(0, s.last.Load()), nil
+}
+
+// Close fecha os dispositivos abertos.
+func (s *InputSource) Close() error {
+	var err error
+	for _, f := range s.files {
+		err = errors.Join(err, f.Close())
+	}
+	return err
+}
@@ -0,0 +1,24 @@
+//go:build !linux
+
+package main
+
+import (
+	"errors"
+	"time"
+)
+
+// InputSource só está disponível no Linux, onde lê /dev/input.
+type InputSource struct{}
+
+// NewInputSource sempre falha fora do Linux.
+func NewInputSource(pattern string, now func() time.Time) (*InputSource, error) {
+	return nil, errors.New("fonte de entrada disponível apenas no Linux")
+}
+
+func (s *InputSource) LastActivity() (time.Time, error) {
+	return time.Time{}, errors.New("fonte de entrada disponível apenas no Linux")
+}
+
+func (s *InputSource) Close() error {
+	return nil
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// MarshalText grava o estado no diário pelo nome.
+func (s State) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText lê o estado gravado por MarshalText.
+func (s *State) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "ativo":
+		*s = StateActive
+	case "ausente":
+		*s = StateAway
+	case "parado":
+		*s = StateStopped
+	default:
+		return fmt.Errorf("estado desconhecido %q", text)
+	}
+	return nil
+}
+
+// heartbeatInterval é o intervalo entre os batimentos gravados no diário
+// enquanto o monitor roda. Se o monitor morrer sem gravar "parado", o último
+// estado só conta até o último registro mais este intervalo.
+const heartbeatInterval = time.Minute
+
+// JournalEntry é uma linha do diário: a partir de Time o usuário está em State.
+// Batimentos (Heartbeat) só confirmam que o monitor ainda rodava em Time, no
+// mesmo estado da entrada anterior.
+type JournalEntry struct {
+	Time      time.Time `json:"time"`
+	State     State     `json:"state"`
+	Heartbeat bool      `json:"heartbeat,omitempty"`
+}
+
+// Journal é um diário de transições só de acréscimo, uma entrada JSON por linha.
+type Journal struct {
+	f *os.File
+}
+
+// OpenJournal abre (ou cria) o diário para acrescentar entradas.
+func OpenJournal(path string) (*Journal, error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &Journal{f: f}, nil
+}
+
+// Append grava a entrada e força a escrita em disco, para não perder
+// transições se o computador for desligado.
+func (j *Journal) Append(e JournalEntry) error {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	if _, err := j.f.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	return j.f.Sync()
+}
+
+// Close fecha o arquivo do diário.
+func (j *Journal) Close() error {
+	return j.f.Close()
+}
+
+// ReadJournal lê todas as entradas de um diário.
+func ReadJournal(r io.Reader) ([]JournalEntry, error) {
+	var entries []JournalEntry
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var e JournalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("linha %d: %w", line, err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, scanner.Err()
+}
+
+// ReadJournalFile lê todas as entradas do diário em path.
+func ReadJournalFile(path string) ([]JournalEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadJournal(f)
+}
@@ -1,33 +1,206 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
-
-	"github.com/go-vgo/robotgo"
 )
 
+// Diário padrão de transições, no diretório atual
+const defaultJournal = "atividade.jsonl"
+
 func main() {
-	lastActivity := time.Now()
-	var xold, yold int
+	os.Exit(run())
+}
+
+// run executa o monitor (ou o subcomando report) e retorna o código de
+// saída; main só chama os.Exit depois que os defers de run fecharam o
+// diário e os dispositivos de entrada.
+func run() int {
+	if len(os.Args) > 1 && os.Args[1] == "report" {
+		if err := runReport(os.Args[2:]); err != nil {
+			fmt.Println("Erro:", err)
+			return 1
+		}
+		return 0
+	}
+
+	defaults := DefaultConfig()
+	configPath := flag.String("config", "", "arquivo de configuração YAML")
+	sourceName := flag.String("source", defaults.Source, "fonte de atividade: mouse (robotgo) ou input (/dev/input, teclado e mouse, Linux)")
+	journalPath := flag.String("journal", defaults.Journal, "arquivo do diário de transições (JSON por linha)")
+	poll := flag.Duration("poll", defaults.PollInterval, "intervalo entre verificações de atividade")
+	idle := flag.Duration("idle", defaults.IdleThreshold, "tempo sem atividade para considerar o usuário ausente")
+	longAway := flag.Duration("long-away", defaults.LongAwayThreshold, "tempo de ausência que dispara o evento long_away (0 desativa)")
+	flag.Parse()
+
+	cfg := defaults
+	if *configPath != "" {
+		var err error
+		if cfg, err = LoadConfig(*configPath); err != nil {
+			fmt.Println("Erro ao ler a configuração:", err)
+			return 2
+		}
+	}
+
+	// Flags informadas explicitamente têm prioridade sobre o arquivo
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "source":
+			cfg.Source = *sourceName
+		case "journal":
+			cfg.Journal = *journalPath
+		case "poll":
+			cfg.PollInterval = *poll
+		case "idle":
+			cfg.IdleThreshold = *idle
+		case "long-away":
+			cfg.LongAwayThreshold = *longAway
+		}
+	})
+	if err := cfg.Validate(); err != nil {
+		fmt.Println("Erro na configuração:", err)
+		return 2
+	}
+
+	var source ActivitySource
+	switch cfg.Source {
+	case "mouse":
+		source = NewMouseSource(time.Now)
+	case "input":
+		input, err := NewInputSource("/dev/input/event*", time.Now)
+		if err != nil {
+			fmt.Println("Erro ao abrir dispositivos de entrada:", err)
+			return 1
+		}
+		defer input.Close()
+		source = input
+	default:
+		fmt.Printf("Erro: fonte desconhecida %q\n", cfg.Source)
+		return 2
+	}
+
+	journal, err := OpenJournal(cfg.Journal)
+	if err != nil {
+		fmt.Println("Erro ao abrir o diário:", err)
+		return 1
+	}
+	defer journal.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	monitor := NewMonitor(source, cfg.IdleThreshold, time.Now)
+	monitor.LongAwayThreshold = cfg.LongAwayThreshold
+
+	hooks := NewHookRunner(cfg.Hooks)
+	err = monitorLoop(ctx, monitor, journal, hooks, cfg.PollInterval)
+
+	// Dá aos ganchos disparados por último (por exemplo, o da volta do
+	// usuário) a chance de terminar antes de sair
+	waitCtx, cancel := context.WithTimeout(context.Background(), hooks.Timeout)
+	defer cancel()
+	if err := hooks.Wait(waitCtx); err != nil {
+		fmt.Println("Ganchos ainda em execução ao encerrar:", err)
+	}
+
+	if err != nil {
+		fmt.Println("Erro:", err)
+		return 1
+	}
+	return 0
+}
+
+// monitorLoop verifica a atividade a cada intervalo até o contexto ser
+// cancelado, gravando no diário o estado inicial, cada transição, um
+// batimento a cada heartbeatInterval e o encerramento, e disparando os
+// ganchos de cada evento.
+func monitorLoop(ctx context.Context, monitor *Monitor, journal *Journal, hooks *HookRunner, pollInterval time.Duration) error {
+	state, since := monitor.State()
+	if err := journal.Append(JournalEntry{Time: since, State: state}); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
 	for {
-		// Verifica a posição do mouse a cada 5 segundos
-		x, y := robotgo.Location()
+		select {
+		case <-ctx.Done():
+			return journal.Append(JournalEntry{Time: monitor.Now(), State: StateStopped})
+		case <-heartbeat.C:
+			state, _ := monitor.State()
+			if err := journal.Append(JournalEntry{Time: monitor.Now(), State: state, Heartbeat: true}); err != nil {
+				return err
+			}
+			continue
+		case <-ticker.C:
+		}
+
+		t, changed, err := monitor.Check()
+		if err != nil {
+			fmt.Println("Erro ao verificar atividade:", err)
+			continue
+		}
 
-		fmt.Println(x, y)
-		// Se a posição do mouse mudou, o usuário está ativo
-		if x != xold || y != yold {
-			lastActivity = time.Now()
-			fmt.Println("Usuário ativo!")
+		if changed {
+			if t.To == StateActive {
+				fmt.Println("Usuário ativo!")
+				hooks.Fire(HookPayload{Event: EventActive, State: t.To, Since: t.At})
+			} else {
+				fmt.Println("Usuário ausente!")
+				hooks.Fire(HookPayload{Event: EventIdle, State: t.To, Since: t.At})
+			}
+			if err := journal.Append(JournalEntry{Time: t.At, State: t.To}); err != nil {
+				return err
+			}
 		}
 
-		// Verifica a ociosidade
-		if time.Since(lastActivity) > 7*time.Second {
-			fmt.Println("Usuário ausente!")
+		if away, ok := monitor.LongAway(); ok {
+			fmt.Printf("Usuário ausente há %s!\n", away.Round(time.Second))
+			_, since := monitor.State()
+			hooks.Fire(HookPayload{Event: EventLongAway, State: StateAway, Since: since, AwaySeconds: int64(away.Seconds())})
 		}
+	}
+}
+
+// runReport implementa o subcomando `report`.
+func runReport(args []string) error {
+	flags := flag.NewFlagSet("report", flag.ExitOnError)
+	journalPath := flags.String("journal", defaultJournal, "arquivo do diário de transições")
+	date := flags.String("date", time.Now().Format("2006-01-02"), "dia do relatório (AAAA-MM-DD)")
+	csvPath := flags.String("csv", "", "exporta os períodos do dia para este arquivo CSV")
+	flags.Parse(args)
+
+	day, err := time.ParseInLocation("2006-01-02", *date, time.Local)
+	if err != nil {
+		return fmt.Errorf("data inválida: %w", err)
+	}
+
+	entries, err := ReadJournalFile(*journalPath)
+	if err != nil {
+		return err
+	}
 
-		xold, yold = x, y
+	report := BuildReport(entries, day, time.Now())
+	WriteSummary(os.Stdout, report)
 
-		time.Sleep(5 * time.Second)
+	if *csvPath == "" {
+		return nil
+	}
+	f, err := os.Create(*csvPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := WriteCSV(f, report); err != nil {
+		return err
 	}
+	fmt.Println("Relatório salvo em", *csvPath)
+	return nil
 }
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"time"
+)
+
+// ActivitySource informa o momento da última atividade do usuário.
+type ActivitySource interface {
+	LastActivity() (time.Time, error)
+}
+
+// State é o estado do usuário observado pelo monitor.
+type State int
+
+const (
+	StateActive State = iota
+	StateAway
+	// StateStopped marca no diário o momento em que o monitor foi encerrado,
+	// para que o tempo sem monitoramento não conte como ativo nem ausente.
+	StateStopped
+)
+
+func (s State) String() string {
+	switch s {
+	case StateAway:
+		return "ausente"
+	case StateStopped:
+		return "parado"
+	default:
+		return "ativo"
+	}
+}
+
+// Transition representa a mudança de estado detectada em uma verificação.
+// At é o momento em que o novo estado começou de fato: a última atividade
+// antes da ausência ou a primeira atividade na volta.
+type Transition struct {
+	From State
+	To   State
+	At   time.Time
+}
+
+// Monitor é a máquina de estados ativo/ausente. Cada Check faz uma única
+// leitura de Source e compara o tempo ocioso com IdleThreshold; o intervalo
+// entre as verificações e a hora atual (Now) ficam a cargo de quem o usa.
+//
+// LongAwayThreshold, se maior que zero, é o tempo de ausência a partir do qual
+// LongAway passa a reportar uma ausência longa.
+type Monitor struct {
+	Source            ActivitySource
+	IdleThreshold     time.Duration
+	LongAwayThreshold time.Duration
+	Now               func() time.Time
+
+	state            State
+	since            time.Time
+	longAwayReported bool
+}
+
+// NewMonitor cria um monitor que começa no estado ativo.
+func NewMonitor(source ActivitySource, idleThreshold time.Duration, now func() time.Time) *Monitor {
+	return &Monitor{
+		Source:        source,
+		IdleThreshold: idleThreshold,
+		Now:           now,
+		state:         StateActive,
+		since:         now(),
+	}
+}
+
+// State retorna o estado atual e desde quando ele vale.
+func (m *Monitor) State() (State, time.Time) {
+	return m.state, m.since
+}
+
+// Check consulta a fonte de atividade e retorna a transição, se houver.
+func (m *Monitor) Check() (Transition, bool, error) {
+	last, err := m.Source.LastActivity()
+	if err != nil {
+		return Transition{}, false, err
+	}
+
+	idle := m.Now().Sub(last)
+	next := StateActive
+	if idle > m.IdleThreshold {
+		next = StateAway
+	}
+	if next == m.state {
+		return Transition{}, false, nil
+	}
+
+	// A última atividade pode ser anterior ao início do estado atual (por
+	// exemplo, logo após iniciar o monitor); a transição nunca volta no tempo
+	at := last
+	if at.Before(m.since) {
+		at = m.since
+	}
+	t := Transition{From: m.state, To: next, At: at}
+	m.state, m.since = next, at
+	m.longAwayReported = false
+	return t, true, nil
+}
+
+// LongAway informa, uma única vez por período de ausência, quando o usuário
+// está ausente há mais que LongAwayThreshold. Retorna também há quanto tempo.
+func (m *Monitor) LongAway() (time.Duration, bool) {
+	if m.state != StateAway || m.LongAwayThreshold <= 0 || m.longAwayReported {
+		return 0, false
+	}
+	away := m.Now().Sub(m.since)
+	if away <= m.LongAwayThreshold {
+		return 0, false
+	}
+	m.longAwayReported = true
+	return away, true
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// FakeClock é um relógio controlado manualmente.
+type FakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewFakeClock cria um relógio parado em start.
+func NewFakeClock(start time.Time) *FakeClock {
+	return &FakeClock{now: start}
+}
+
+// Now retorna o horário atual do relógio.
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Advance avança o relógio em d.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// FakeSource é uma fonte de atividade controlada manualmente.
+type FakeSource struct {
+	Clock *FakeClock
+	Err   error
+
+	mu   sync.Mutex
+	last time.Time
+}
+
+// NewFakeSource cria uma fonte cuja última atividade é o horário atual do relógio.
+func NewFakeSource(clock *FakeClock) *FakeSource {
+	return &FakeSource{Clock: clock, last: clock.Now()}
+}
+
+// Touch registra uma atividade no horário atual do relógio.
+func (s *FakeSource) Touch() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.last = s.Clock.Now()
+}
+
+// LastActivity retorna a última atividade registrada por Touch, ou Err se definido.
+func (s *FakeSource) LastActivity() (time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return time.Time{}, s.Err
+	}
+	return s.last, nil
+}
+
+var testStart = time.Date(2024, 10, 7, 9, 0, 0, 0, time.UTC)
+
+// check avança o relógio em d e verifica o monitor, falhando o teste em caso de erro.
+func check(t *testing.T, m *Monitor, clock *FakeClock, d time.Duration) (Transition, bool) {
+	t.Helper()
+	clock.Advance(d)
+	tr, changed, err := m.Check()
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	return tr, changed
+}
+
+func TestMonitorTransitions(t *testing.T) {
+	clock := NewFakeClock(testStart)
+	source := NewFakeSource(clock)
+	m := NewMonitor(source, 7*time.Second, clock.Now)
+
+	if state, since := m.State(); state != StateActive || !since.Equal(testStart) {
+		t.Fatalf("estado inicial = %s desde %v", state, since)
+	}
+
+	// Atividade recente: continua ativo
+	clock.Advance(3 * time.Second)
+	source.Touch()
+	lastTouch := clock.Now()
+	if _, changed := check(t, m, clock, 5*time.Second); changed {
+		t.Fatal("mudou de estado antes do limite de inatividade")
+	}
+
+	// Exatamente no limite ainda é ativo; passou dele, ausente desde a última atividade
+	if _, changed := check(t, m, clock, 2*time.Second); changed {
+		t.Fatal("mudou de estado exatamente no limite de inatividade")
+	}
+	tr, changed := check(t, m, clock, time.Second)
+	if want := (Transition{From: StateActive, To: StateAway, At: lastTouch}); !changed || tr != want {
+		t.Fatalf("transição = %+v (%v), quer %+v", tr, changed, want)
+	}
+	if state, since := m.State(); state != StateAway || !since.Equal(lastTouch) {
+		t.Fatalf("estado = %s desde %v", state, since)
+	}
+
+	// Continua ausente sem repetir a transição
+	if _, changed := check(t, m, clock, time.Minute); changed {
+		t.Fatal("repetiu a transição para ausente")
+	}
+
+	// Volta: ativo desde a nova atividade
+	clock.Advance(10 * time.Second)
+	source.Touch()
+	back := clock.Now()
+	tr, changed = check(t, m, clock, time.Second)
+	if want := (Transition{From: StateAway, To: StateActive, At: back}); !changed || tr != want {
+		t.Fatalf("transição = %+v (%v), quer %+v", tr, changed, want)
+	}
+
+	// E sai de novo
+	tr, changed = check(t, m, clock, 8*time.Second)
+	if want := (Transition{From: StateActive, To: StateAway, At: back}); !changed || tr != want {
+		t.Fatalf("transição = %+v (%v), quer %+v", tr, changed, want)
+	}
+}
+
+func TestMonitorNeverGoesBackInTime(t *testing.T) {
+	clock := NewFakeClock(testStart)
+	source := NewFakeSource(clock)
+	clock.Advance(time.Hour)
+	// A última atividade é de antes de o monitor iniciar
+	m := NewMonitor(source, 7*time.Second, clock.Now)
+
+	tr, changed := check(t, m, clock, time.Second)
+	if !changed || tr.To != StateAway || !tr.At.Equal(testStart.Add(time.Hour)) {
+		t.Fatalf("transição = %+v (%v), quer ausente desde o início do monitor", tr, changed)
+	}
+}
+
+func TestMonitorLongAway(t *testing.T) {
+	clock := NewFakeClock(testStart)
+	source := NewFakeSource(clock)
+	m := NewMonitor(source, 7*time.Second, clock.Now)
+	m.LongAwayThreshold = time.Minute
+
+	if _, ok := m.LongAway(); ok {
+		t.Fatal("ausência longa enquanto ativo")
+	}
+	check(t, m, clock, 10*time.Second)
+	if _, ok := m.LongAway(); ok {
+		t.Fatal("ausência longa antes do limite")
+	}
+
+	clock.Advance(time.Minute)
+	away, ok := m.LongAway()
+	if !ok || away != 70*time.Second {
+		t.Fatalf("LongAway() = %v, %v; quer 1m10s, true", away, ok)
+	}
+	clock.Advance(time.Minute)
+	if _, ok := m.LongAway(); ok {
+		t.Fatal("ausência longa reportada duas vezes")
+	}
+
+	// Uma nova ausência volta a ser reportada
+	source.Touch()
+	check(t, m, clock, time.Second)
+	check(t, m, clock, 2*time.Minute)
+	if _, ok := m.LongAway(); !ok {
+		t.Fatal("nova ausência longa não reportada")
+	}
+}
+
+func TestMonitorSourceError(t *testing.T) {
+	clock := NewFakeClock(testStart)
+	source := NewFakeSource(clock)
+	m := NewMonitor(source, 7*time.Second, clock.Now)
+
+	source.Err = errors.New("sem acesso")
+	clock.Advance(time.Minute)
+	if _, changed, err := m.Check(); err != source.Err || changed {
+		t.Fatalf("Check() = %v, %v; quer o erro da fonte", changed, err)
+	}
+	if state, _ := m.State(); state != StateActive {
+		t.Fatalf("estado = %s após erro, quer ativo", state)
+	}
+}
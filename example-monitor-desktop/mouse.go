@@ -0,0 +1,31 @@
+package main
+
+import (
+	"time"
+
+	"github.com/go-vgo/robotgo"
+)
+
+// MouseSource detecta atividade comparando a posição do mouse entre consultas.
+// Teclado não é detectado; para isso use InputSource.
+type MouseSource struct {
+	Now func() time.Time
+
+	x, y int
+	last time.Time
+}
+
+// NewMouseSource cria uma fonte baseada na posição do mouse via robotgo.
+func NewMouseSource(now func() time.Time) *MouseSource {
+	return &MouseSource{Now: now, last: now()}
+}
+
+// LastActivity lê a posição do mouse; se ela mudou, o usuário está ativo agora.
+func (s *MouseSource) LastActivity() (time.Time, error) {
+	x, y := robotgo.Location()
+	if x != s.x || y != s.y {
+		s.last = s.Now()
+	}
+	s.x, s.y = x, y
+	return s.last, nil
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// Period é um intervalo contínuo em um mesmo estado.
+type Period struct {
+	State State
+	Start time.Time
+	End   time.Time
+}
+
+// Duration retorna a duração do período.
+func (p Period) Duration() time.Duration {
+	return p.End.Sub(p.Start)
+}
+
+// DailyReport resume um dia do diário.
+// LongestFocus é o maior período ativo sem interrupção.
+type DailyReport struct {
+	Day          time.Time
+	Active       time.Duration
+	Away         time.Duration
+	Periods      []Period
+	LongestFocus Period
+}
+
+// IdlePeriods retorna apenas os períodos de ausência.
+func (r DailyReport) IdlePeriods() []Period {
+	var idle []Period
+	for _, p := range r.Periods {
+		if p.State == StateAway {
+			idle = append(idle, p)
+		}
+	}
+	return idle
+}
+
+// BuildReport monta o relatório do dia que contém day, no fuso de day.
+// A última entrada do diário vale até now, o que permite gerar o relatório
+// do dia corrente com o monitor ainda rodando. Períodos em que o monitor
+// estava parado ficam de fora, inclusive quando ele foi morto sem gravar
+// "parado": cada período termina no máximo heartbeatInterval depois do
+// último batimento.
+func BuildReport(entries []JournalEntry, day, now time.Time) DailyReport {
+	start := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+	end := start.AddDate(0, 0, 1)
+	report := DailyReport{Day: start}
+
+	for i := 0; i < len(entries); {
+		e := entries[i]
+
+		// Pula os batimentos do período, guardando o último
+		lastSeen := e.Time
+		i++
+		for i < len(entries) && entries[i].Heartbeat {
+			lastSeen = entries[i].Time
+			i++
+		}
+		if e.State == StateStopped {
+			continue
+		}
+		periodEnd := now
+		if i < len(entries) {
+			periodEnd = entries[i].Time
+		}
+		if limit := lastSeen.Add(heartbeatInterval); periodEnd.After(limit) {
+			periodEnd = limit
+		}
+
+		// Recorta o período para dentro do dia
+		p := Period{State: e.State, Start: e.Time, End: periodEnd}
+		if p.Start.Before(start) {
+			p.Start = start
+		}
+		if p.End.After(end) {
+			p.End = end
+		}
+		if !p.End.After(p.Start) {
+			continue
+		}
+
+		// Entradas repetidas no mesmo estado (por exemplo, após reiniciar o
+		// monitor) continuam o período anterior
+		if n := len(report.Periods); n > 0 && report.Periods[n-1].State == p.State && report.Periods[n-1].End.Equal(p.Start) {
+			report.Periods[n-1].End = p.End
+		} else {
+			report.Periods = append(report.Periods, p)
+		}
+	}
+
+	for _, p := range report.Periods {
+		switch p.State {
+		case StateActive:
+			report.Active += p.Duration()
+			if p.Duration() > report.LongestFocus.Duration() {
+				report.LongestFocus = p
+			}
+		case StateAway:
+			report.Away += p.Duration()
+		}
+	}
+	return report
+}
+
+// WriteSummary escreve o resumo do relatório em texto.
+func WriteSummary(w io.Writer, r DailyReport) {
+	fmt.Fprintf(w, "Relatório de %s\n", r.Day.Format("02/01/2006"))
+	fmt.Fprintf(w, "Tempo ativo:   %s\n", r.Active.Round(time.Second))
+	fmt.Fprintf(w, "Tempo ausente: %s\n", r.Away.Round(time.Second))
+	if r.LongestFocus.Duration() > 0 {
+		fmt.Fprintf(w, "Maior bloco de foco: %s (%s - %s)\n", r.LongestFocus.Duration().Round(time.Second),
+			r.LongestFocus.Start.Format("15:04:05"), r.LongestFocus.End.Format("15:04:05"))
+	}
+
+	idle := r.IdlePeriods()
+	fmt.Fprintf(w, "Períodos de ausência: %d\n", len(idle))
+	for _, p := range idle {
+		fmt.Fprintf(w, "  %s - %s (%s)\n", p.Start.Format("15:04:05"), p.End.Format("15:04:05"), p.Duration().Round(time.Second))
+	}
+}
+
+// WriteCSV exporta os períodos do relatório em CSV, um período por linha.
+func WriteCSV(w io.Writer, r DailyReport) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"data", "estado", "inicio", "fim", "duracao_segundos"})
+	for _, p := range r.Periods {
+		cw.Write([]string{
+			r.Day.Format("2006-01-02"),
+			p.State.String(),
+			p.Start.Format(time.RFC3339),
+			p.End.Format(time.RFC3339),
+			strconv.FormatInt(int64(p.Duration().Seconds()), 10),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func at(hhmm string) time.Time {
+	t, err := time.ParseInLocation("2006-01-02 15:04", "2024-10-07 "+hhmm, time.UTC)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestBuildReport(t *testing.T) {
+	entries := []JournalEntry{
+		{Time: at("09:00"), State: StateActive},
+		{Time: at("09:01"), State: StateActive, Heartbeat: true},
+		{Time: at("09:02"), State: StateActive, Heartbeat: true},
+		{Time: at("09:02"), State: StateAway},
+		{Time: at("09:03"), State: StateAway, Heartbeat: true},
+		{Time: at("09:04"), State: StateActive},
+		{Time: at("09:05"), State: StateStopped},
+		// Reinício sem parado no meio: o monitor foi morto depois de 10:01
+		{Time: at("10:00"), State: StateActive},
+		{Time: at("10:01"), State: StateActive, Heartbeat: true},
+		{Time: at("12:00"), State: StateAway},
+	}
+	r := BuildReport(entries, at("00:00"), at("12:30"))
+
+	want := []Period{
+		{StateActive, at("09:00"), at("09:02")},
+		{StateAway, at("09:02"), at("09:04")},
+		{StateActive, at("09:04"), at("09:05")},
+		{StateActive, at("10:00"), at("10:02")},
+		{StateAway, at("12:00"), at("12:01")},
+	}
+	if len(r.Periods) != len(want) {
+		t.Fatalf("períodos = %+v, quer %+v", r.Periods, want)
+	}
+	for i := range want {
+		if r.Periods[i] != want[i] {
+			t.Errorf("período %d = %+v, quer %+v", i, r.Periods[i], want[i])
+		}
+	}
+	if r.Active != 5*time.Minute || r.Away != 3*time.Minute {
+		t.Errorf("ativo %v, ausente %v; quer 5m e 3m", r.Active, r.Away)
+	}
+	if r.LongestFocus != want[0] {
+		t.Errorf("maior foco = %+v, quer %+v", r.LongestFocus, want[0])
+	}
+}
+
+func TestBuildReportRunning(t *testing.T) {
+	// Com o monitor rodando, o último estado vale até agora
+	entries := []JournalEntry{
+		{Time: at("09:00"), State: StateActive},
+		{Time: at("09:01"), State: StateActive, Heartbeat: true},
+	}
+	r := BuildReport(entries, at("00:00"), at("09:01").Add(30*time.Second))
+	if r.Active != 90*time.Second {
+		t.Errorf("ativo = %v, quer 1m30s", r.Active)
+	}
+}
+
+func TestBuildReportClipsToDay(t *testing.T) {
+	entries := []JournalEntry{
+		{Time: at("00:00").Add(-time.Minute), State: StateAway},
+		{Time: at("00:00").Add(30 * time.Second), State: StateAway, Heartbeat: true},
+	}
+	r := BuildReport(entries, at("00:00"), at("12:00"))
+	if len(r.Periods) != 1 || !r.Periods[0].Start.Equal(at("00:00")) || r.Away != 90*time.Second {
+		t.Errorf("períodos = %+v, ausente %v", r.Periods, r.Away)
+	}
+}
@@ -0,0 +1,304 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// API expõe os lembretes em HTTP/JSON. Os lembretes criados, alterados,
+// pausados ou apagados ficam no Store; adiamentos e pulos valem só até o
+// programa reiniciar, como os comandos da entrada padrão.
+type API struct {
+	Scheduler  *Scheduler
+	Store      *Store
+	Dispatcher *Dispatcher
+
+	mu sync.Mutex // mantém Store e Scheduler de acordo durante as alterações
+}
+
+// ReminderInfo é um lembrete como a API o devolve: a configuração e o
+// próximo disparo.
+type ReminderInfo struct {
+	StoredReminder
+	Next    *time.Time `json:"next,omitempty"` // ausente se não houver próximo disparo
+	Snoozed bool       `json:"snoozed,omitempty"`
+}
+
+// Handler retorna o roteador da API.
+func (a *API) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /reminders", a.handleList)
+	mux.HandleFunc("POST /reminders", a.handleCreate)
+	mux.HandleFunc("GET /reminders/{name}", a.handleGet)
+	mux.HandleFunc("PUT /reminders/{name}", a.handleUpdate)
+	mux.HandleFunc("DELETE /reminders/{name}", a.handleDelete)
+	mux.HandleFunc("POST /reminders/{name}/pause", a.handlePause(true))
+	mux.HandleFunc("POST /reminders/{name}/resume", a.handlePause(false))
+	mux.HandleFunc("POST /reminders/{name}/snooze", a.handleSnooze)
+	mux.HandleFunc("POST /reminders/{name}/skip", a.handleSkip)
+	mux.HandleFunc("POST /reminders/{name}/ack", a.handleAckLatest)
+	mux.HandleFunc("GET /deliveries", a.handleDeliveries)
+	mux.HandleFunc("POST /deliveries/{id}/ack", a.handleAck)
+	return mux
+}
+
+// handleList responde com os lembretes na ordem do próximo disparo.
+func (a *API) handleList(w http.ResponseWriter, r *http.Request) {
+	list := []ReminderInfo{}
+	for _, st := range a.Scheduler.Upcoming() {
+		sr, err := a.Store.Reminder(st.Name)
+		if err != nil {
+			continue // apagado entre as duas leituras
+		}
+		list = append(list, reminderInfo(sr, st))
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+func (a *API) handleGet(w http.ResponseWriter, r *http.Request) {
+	a.respondReminder(w, http.StatusOK, r.PathValue("name"))
+}
+
+func (a *API) handleCreate(w http.ResponseWriter, r *http.Request) {
+	var rc ReminderConfig
+	if !readJSON(w, r, &rc) {
+		return
+	}
+	reminder, ok := a.reminder(w, rc)
+	if !ok {
+		return
+	}
+
+	// O lembrete entra primeiro na agenda: se a gravação falhar, ele sai de
+	// novo, e o arquivo nunca fica com um lembrete que não dispara.
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if _, err := a.Scheduler.Add(reminder); err != nil {
+		writeError(w, err)
+		return
+	}
+	if err := a.Store.AddReminders(rc); err != nil {
+		a.Scheduler.Remove(rc.Name)
+		writeError(w, err)
+		return
+	}
+	a.respondReminder(w, http.StatusCreated, rc.Name)
+}
+
+// handleUpdate substitui a configuração do lembrete. O nome vem do caminho;
+// se vier também no corpo, precisa ser o mesmo.
+func (a *API) handleUpdate(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	var rc ReminderConfig
+	if !readJSON(w, r, &rc) {
+		return
+	}
+	if rc.Name != "" && rc.Name != name {
+		writeErrorStatus(w, http.StatusBadRequest, "não é possível renomear um lembrete")
+		return
+	}
+	rc.Name = name
+	reminder, ok := a.reminder(w, rc)
+	if !ok {
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if _, err := a.Store.UpdateReminder(rc); err != nil {
+		writeError(w, err)
+		return
+	}
+	if _, err := a.Scheduler.Update(reminder); err != nil {
+		writeError(w, err)
+		return
+	}
+	a.respondReminder(w, http.StatusOK, name)
+}
+
+func (a *API) handleDelete(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err := a.Store.DeleteReminder(name); err != nil {
+		writeError(w, err)
+		return
+	}
+	a.Scheduler.Remove(name)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (a *API) handlePause(paused bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name := r.PathValue("name")
+
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		if _, err := a.Store.SetPaused(name, paused); err != nil {
+			writeError(w, err)
+			return
+		}
+		if _, err := a.Scheduler.SetPaused(name, paused); err != nil {
+			writeError(w, err)
+			return
+		}
+		a.respondReminder(w, http.StatusOK, name)
+	}
+}
+
+// handleSnooze adia o próximo disparo. O corpo é opcional:
+// {"duration": "10m"}, o padrão.
+func (a *API) handleSnooze(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	body := struct {
+		Duration Duration `json:"duration"`
+	}{Duration: Duration(10 * time.Minute)}
+	if !readOptionalJSON(w, r, &body) {
+		return
+	}
+	if err := checkSnooze(time.Duration(body.Duration)); err != nil {
+		writeErrorStatus(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if _, err := a.Scheduler.Snooze(name, time.Duration(body.Duration)); err != nil {
+		writeError(w, err)
+		return
+	}
+	a.respondReminder(w, http.StatusOK, name)
+}
+
+func (a *API) handleSkip(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if _, err := a.Scheduler.Skip(name); err != nil {
+		writeError(w, err)
+		return
+	}
+	a.respondReminder(w, http.StatusOK, name)
+}
+
+// handleDeliveries lista as entregas, da mais recente para a mais antiga.
+// Aceita ?reminder=nome e ?pending=true.
+func (a *API) handleDeliveries(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	pending := false
+	if v := q.Get("pending"); v != "" {
+		var err error
+		if pending, err = strconv.ParseBool(v); err != nil {
+			writeErrorStatus(w, http.StatusBadRequest, "pending inválido: "+v)
+			return
+		}
+	}
+	writeJSON(w, http.StatusOK, a.Store.Deliveries(q.Get("reminder"), pending))
+}
+
+func (a *API) handleAck(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeErrorStatus(w, http.StatusBadRequest, "id inválido: "+r.PathValue("id"))
+		return
+	}
+	d, err := a.Store.Ack(id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, d)
+}
+
+// handleAckLatest confirma a entrega pendente mais recente do lembrete.
+func (a *API) handleAckLatest(w http.ResponseWriter, r *http.Request) {
+	d, err := a.Store.AckLatest(r.PathValue("name"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, d)
+}
+
+// reminder valida a configuração recebida e os canais que ela usa. Em caso
+// de erro já responde 400.
+func (a *API) reminder(w http.ResponseWriter, rc ReminderConfig) (Reminder, bool) {
+	reminder, err := rc.Reminder()
+	if err == nil {
+		err = a.Dispatcher.Validate([]Reminder{reminder})
+	}
+	if err != nil {
+		writeErrorStatus(w, http.StatusBadRequest, err.Error())
+		return Reminder{}, false
+	}
+	return reminder, true
+}
+
+func (a *API) respondReminder(w http.ResponseWriter, code int, name string) {
+	sr, err := a.Store.Reminder(name)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	st, err := a.Scheduler.Status(name)
+	if err != nil {
+		writeError(w, fmt.Errorf("lembrete %q %w", name, ErrNotFound))
+		return
+	}
+	writeJSON(w, code, reminderInfo(sr, st))
+}
+
+func reminderInfo(sr StoredReminder, st Status) ReminderInfo {
+	info := ReminderInfo{StoredReminder: sr, Snoozed: st.Snoozed}
+	if !st.Next.IsZero() {
+		info.Next = &st.Next
+	}
+	return info
+}
+
+// readJSON decodifica o corpo em v, rejeitando campos desconhecidos. Em caso
+// de erro já responde 400.
+func readJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	return decodeJSON(w, r, v, false)
+}
+
+// readOptionalJSON é como readJSON, mas aceita o corpo vazio e deixa v como
+// está. O tamanho do corpo não serve para isso: com chunked ele é -1.
+func readOptionalJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	return decodeJSON(w, r, v, true)
+}
+
+func decodeJSON(w http.ResponseWriter, r *http.Request, v any, optional bool) bool {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20))
+	dec.DisallowUnknownFields()
+	err := dec.Decode(v)
+	if err == nil || optional && errors.Is(err, io.EOF) {
+		return true
+	}
+	writeErrorStatus(w, http.StatusBadRequest, "JSON inválido: "+err.Error())
+	return false
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError responde 404 ou 409 para os erros do Store e 500 para os demais.
+func writeError(w http.ResponseWriter, err error) {
+	code := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, ErrNotFound):
+		code = http.StatusNotFound
+	case errors.Is(err, ErrExists):
+		code = http.StatusConflict
+	}
+	writeErrorStatus(w, code, err.Error())
+}
+
+func writeErrorStatus(w http.ResponseWriter, code int, msg string) {
+	writeJSON(w, code, map[string]string{"error": msg})
+}
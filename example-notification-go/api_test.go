@@ -0,0 +1,94 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testAPI monta a API sobre um Store em path e uma agenda vazia.
+func testAPI(t *testing.T, path string, clock *FakeClock) *API {
+	t.Helper()
+	return &API{Scheduler: mustScheduler(t, clock), Store: mustStore(t, path), Dispatcher: &Dispatcher{}}
+}
+
+// call faz a requisição direto no Handler. Sem tamanho conhecido
+// (ContentLength -1), o corpo chega como numa requisição chunked.
+func call(a *API, method, target, body string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, target, strings.NewReader(body))
+	r.ContentLength = -1
+	w := httptest.NewRecorder()
+	a.Handler().ServeHTTP(w, r)
+	return w
+}
+
+func TestAPICreateSaveError(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "dados")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	a := testAPI(t, filepath.Join(dir, "lembretes.json"), NewFakeClock(day(7, 9, 0)))
+	body := `{"name": "agua", "every": "30m", "message": "Beba água"}`
+
+	// Sem gravar, o lembrete também não fica na agenda
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatal(err)
+	}
+	if w := call(a, "POST", "/reminders", body); w.Code != http.StatusInternalServerError {
+		t.Fatalf("POST sem o diretório = %d %s, quer 500", w.Code, w.Body)
+	}
+	if _, err := a.Scheduler.Status("agua"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("lembrete ficou na agenda sem ser gravado: %v", err)
+	}
+
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if w := call(a, "POST", "/reminders", body); w.Code != http.StatusCreated {
+		t.Errorf("POST depois da falha = %d %s, quer 201", w.Code, w.Body)
+	}
+}
+
+func TestAPISnooze(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		code int
+		next time.Duration // depois de agora, se code for 200
+	}{
+		{"corpo vazio", "", http.StatusOK, 10 * time.Minute},
+		{"duração", `{"duration": "5m"}`, http.StatusOK, 5 * time.Minute},
+		{"JSON inválido", `{"duration":`, http.StatusBadRequest, 0},
+		{"duração negativa", `{"duration": "-5m"}`, http.StatusBadRequest, 0},
+		{"duração zero", `{"duration": "0s"}`, http.StatusBadRequest, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := NewFakeClock(day(7, 9, 0))
+			a := testAPI(t, filepath.Join(t.TempDir(), "lembretes.json"), clock)
+			if w := call(a, "POST", "/reminders", `{"name": "agua", "every": "30m", "message": "Beba água"}`); w.Code != http.StatusCreated {
+				t.Fatalf("POST = %d %s", w.Code, w.Body)
+			}
+
+			w := call(a, "POST", "/reminders/agua/snooze", tt.body)
+			if w.Code != tt.code {
+				t.Fatalf("snooze = %d %s, quer %d", w.Code, w.Body, tt.code)
+			}
+			if tt.code != http.StatusOK {
+				return
+			}
+			st, err := a.Scheduler.Status("agua")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := clock.Now().Add(tt.next); !st.Snoozed || !st.Next.Equal(want) {
+				t.Errorf("status = %+v, quer soneca até %v", st, want)
+			}
+		})
+	}
+}
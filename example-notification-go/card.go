@@ -0,0 +1,339 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"os"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/fogleman/gg"
+	"github.com/golang/freetype/truetype"
+	"golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+// CardTemplate descreve a aparência do cartão de notificação. Title e Body
+// são templates do text/template que recebem um CardData.
+type CardTemplate struct {
+	Width      int     `yaml:"width"`
+	Height     int     `yaml:"height"`
+	Padding    float64 `yaml:"padding"`
+	Radius     float64 `yaml:"radius"`
+	Background string  `yaml:"background"` // #rgb, #rrggbb ou #rrggbbaa
+	Foreground string  `yaml:"foreground"`
+	Font       string  `yaml:"font"`       // arquivo TTF do corpo; padrão: Go Regular
+	TitleFont  string  `yaml:"title_font"` // arquivo TTF do título; padrão: Go Bold
+	TitleSize  float64 `yaml:"title_size"`
+	BodySize   float64 `yaml:"body_size"`
+	Title      string  `yaml:"title"`
+	Body       string  `yaml:"body"`
+	Icon       string  `yaml:"icon"` // imagem PNG ou JPEG opcional, à esquerda do texto
+	IconSize   int     `yaml:"icon_size"`
+}
+
+// CardData são os valores disponíveis nos templates do cartão.
+type CardData struct {
+	Name    string
+	Message string
+	Time    time.Time
+}
+
+// DefaultCardTemplate é usado quando a configuração não define um cartão.
+func DefaultCardTemplate() CardTemplate {
+	return CardTemplate{
+		Width:      480,
+		Height:     160,
+		Padding:    20,
+		Radius:     16,
+		Background: "#000000cc",
+		Foreground: "#ffffff",
+		TitleSize:  26,
+		BodySize:   16,
+		Title:      "{{.Message}}",
+		Body:       `Lembrete "{{.Name}}" às {{.Time.Format "15:04"}}`,
+		IconSize:   64,
+	}
+}
+
+// maxCardSize limita a largura e a altura do cartão, em pixels.
+const maxCardSize = 2048
+
+// Validate confere os tamanhos do template. Zero é aceito em todos: em
+// NewCardRenderer ele dá lugar ao valor de DefaultCardTemplate, exceto na
+// margem e no raio.
+func (tpl CardTemplate) Validate() error {
+	if tpl.Width < 0 || tpl.Height < 0 || tpl.Width > maxCardSize || tpl.Height > maxCardSize {
+		return fmt.Errorf("cartão: largura e altura devem estar entre 1 e %d pixels", maxCardSize)
+	}
+	if tpl.TitleSize < 0 || tpl.BodySize < 0 || tpl.IconSize < 0 {
+		return errors.New("cartão: tamanhos das fontes e do ícone não podem ser negativos")
+	}
+	if tpl.Padding < 0 || tpl.Radius < 0 {
+		return errors.New("cartão: margem e raio não podem ser negativos")
+	}
+	return nil
+}
+
+// CardRenderer desenha cartões a partir de um CardTemplate. Fontes, cores,
+// ícone e templates são carregados uma vez, em NewCardRenderer.
+type CardRenderer struct {
+	tpl         CardTemplate
+	title, body *template.Template
+	titleFace   font.Face
+	bodyFace    font.Face
+	bg, fg      color.Color
+	icon        image.Image
+}
+
+// NewCardRenderer valida o template e carrega seus recursos. Tamanhos e
+// cores não informados assumem os valores de DefaultCardTemplate; margem e
+// raio zerados valem zero.
+func NewCardRenderer(tpl CardTemplate) (*CardRenderer, error) {
+	def := DefaultCardTemplate()
+	setDefault(&tpl.Width, def.Width)
+	setDefault(&tpl.Height, def.Height)
+	setDefault(&tpl.TitleSize, def.TitleSize)
+	setDefault(&tpl.BodySize, def.BodySize)
+	setDefault(&tpl.IconSize, def.IconSize)
+	if tpl.Background == "" {
+		tpl.Background = def.Background
+	}
+	if tpl.Foreground == "" {
+		tpl.Foreground = def.Foreground
+	}
+	if tpl.Title == "" && tpl.Body == "" {
+		tpl.Title, tpl.Body = def.Title, def.Body
+	}
+	if err := tpl.Validate(); err != nil {
+		return nil, err
+	}
+
+	r := &CardRenderer{tpl: tpl}
+	var err error
+	if r.title, err = template.New("title").Parse(tpl.Title); err != nil {
+		return nil, fmt.Errorf("cartão: title: %w", err)
+	}
+	if r.body, err = template.New("body").Parse(tpl.Body); err != nil {
+		return nil, fmt.Errorf("cartão: body: %w", err)
+	}
+	if r.bg, err = parseColor(tpl.Background); err != nil {
+		return nil, fmt.Errorf("cartão: background: %w", err)
+	}
+	if r.fg, err = parseColor(tpl.Foreground); err != nil {
+		return nil, fmt.Errorf("cartão: foreground: %w", err)
+	}
+	if r.titleFace, err = loadFace(tpl.TitleFont, gobold.TTF, tpl.TitleSize); err != nil {
+		return nil, fmt.Errorf("cartão: title_font: %w", err)
+	}
+	if r.bodyFace, err = loadFace(tpl.Font, goregular.TTF, tpl.BodySize); err != nil {
+		return nil, fmt.Errorf("cartão: font: %w", err)
+	}
+	if tpl.Icon != "" {
+		icon, err := gg.LoadImage(tpl.Icon)
+		if err != nil {
+			return nil, fmt.Errorf("cartão: icon: %w", err)
+		}
+		r.icon = scaleImage(icon, tpl.IconSize)
+	}
+	return r, nil
+}
+
+func setDefault[T int | float64](v *T, def T) {
+	if *v == 0 {
+		*v = def
+	}
+}
+
+// Render desenha o cartão no tamanho do template. Fora dos cantos
+// arredondados a imagem é transparente.
+func (r *CardRenderer) Render(data CardData) (image.Image, error) {
+	title, body, err := r.Text(data)
+	if err != nil {
+		return nil, err
+	}
+	return r.Draw(title, body), nil
+}
+
+// Text aplica os templates de título e corpo, para canais que enviam só
+// texto.
+func (r *CardRenderer) Text(data CardData) (title, body string, err error) {
+	if title, err = execute(r.title, data); err != nil {
+		return "", "", err
+	}
+	if body, err = execute(r.body, data); err != nil {
+		return "", "", err
+	}
+	return title, body, nil
+}
+
+// Draw desenha o cartão com título e corpo já prontos.
+func (r *CardRenderer) Draw(title, body string) image.Image {
+	tpl := r.tpl
+	w, h := float64(tpl.Width), float64(tpl.Height)
+	dc := gg.NewContext(tpl.Width, tpl.Height)
+	dc.DrawRoundedRectangle(0, 0, w, h, tpl.Radius)
+	dc.SetColor(r.bg)
+	dc.Fill()
+
+	x := tpl.Padding
+	if r.icon != nil {
+		dc.DrawImage(r.icon, int(x), int(tpl.Padding))
+		x += float64(tpl.IconSize) + tpl.Padding
+	}
+	width := w - x - tpl.Padding
+	bottom := h - tpl.Padding
+
+	dc.SetColor(r.fg)
+	y := tpl.Padding
+	y = drawLines(dc, r.titleFace, title, x, y, width, bottom)
+	if title != "" && body != "" {
+		y += float64(r.titleFace.Metrics().Height.Ceil()) / 2
+	}
+	drawLines(dc, r.bodyFace, body, x, y, width, bottom)
+	return dc.Image()
+}
+
+func execute(t *template.Template, data CardData) (string, error) {
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("cartão: %w", err)
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// drawLines quebra o texto na largura disponível e desenha a partir de y
+// (topo da primeira linha). Linhas que passariam de bottom são descartadas e
+// a última visível termina com reticências. Retorna o y após a última linha.
+func drawLines(dc *gg.Context, face font.Face, text string, x, y, width, bottom float64) float64 {
+	if text == "" {
+		return y
+	}
+	dc.SetFontFace(face)
+	lineHeight := float64(face.Metrics().Height.Ceil()) * 1.2
+	ascent := float64(face.Metrics().Ascent.Ceil())
+
+	lines := wrapText(dc.MeasureString, text, width)
+	fit := int((bottom - y) / lineHeight)
+	if fit <= 0 {
+		return y
+	}
+	if len(lines) > fit {
+		lines = lines[:fit]
+		lines[fit-1] = ellipsis(dc.MeasureString, lines[fit-1], width)
+	}
+	for _, line := range lines {
+		dc.DrawString(line, x, y+ascent)
+		y += lineHeight
+	}
+	return y
+}
+
+// wrapText quebra o texto em linhas que cabem em width, respeitando as
+// quebras de linha do próprio texto. Palavras maiores que a linha são
+// partidas entre letras.
+func wrapText(measure func(string) (float64, float64), text string, width float64) []string {
+	fits := func(s string) bool {
+		w, _ := measure(s)
+		return w <= width
+	}
+
+	var lines []string
+	for _, paragraph := range strings.Split(text, "\n") {
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			candidate := word
+			if line != "" {
+				candidate = line + " " + word
+			}
+			if fits(candidate) {
+				line = candidate
+				continue
+			}
+			if line != "" {
+				lines = append(lines, line)
+			}
+			// Palavra sozinha maior que a linha: parte em pedaços.
+			line = ""
+			for _, r := range word {
+				if line != "" && !fits(line+string(r)) {
+					lines = append(lines, line)
+					line = ""
+				}
+				line += string(r)
+			}
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// ellipsis encurta a linha até caber com "…" no fim.
+func ellipsis(measure func(string) (float64, float64), line string, width float64) string {
+	runes := []rune(line)
+	for len(runes) > 0 {
+		s := strings.TrimRight(string(runes), " ") + "…"
+		if w, _ := measure(s); w <= width {
+			return s
+		}
+		runes = runes[:len(runes)-1]
+	}
+	return "…"
+}
+
+// loadFace carrega a fonte TTF de path ou, com path vazio, a fonte embutida.
+func loadFace(path string, builtin []byte, size float64) (font.Face, error) {
+	data := builtin
+	if path != "" {
+		var err error
+		if data, err = os.ReadFile(path); err != nil {
+			return nil, err
+		}
+	}
+	f, err := truetype.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	return truetype.NewFace(f, &truetype.Options{Size: size}), nil
+}
+
+// scaleImage redimensiona a imagem para caber em um quadrado de size pixels,
+// mantendo a proporção.
+func scaleImage(src image.Image, size int) image.Image {
+	b := src.Bounds()
+	w, h := size, size
+	if b.Dx() > b.Dy() {
+		h = max(1, size*b.Dy()/b.Dx())
+	} else if b.Dy() > b.Dx() {
+		w = max(1, size*b.Dx()/b.Dy())
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, b, draw.Over, nil)
+	return dst
+}
+
+// parseColor interpreta cores nos formatos #rgb, #rrggbb e #rrggbbaa.
+func parseColor(s string) (color.Color, error) {
+	hex := strings.TrimPrefix(s, "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) == 6 {
+		hex += "ff"
+	}
+	if len(hex) != 8 {
+		return nil, fmt.Errorf("cor inválida %q", s)
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return nil, fmt.Errorf("cor inválida %q", s)
+	}
+	return color.NRGBA{R: uint8(v >> 24), G: uint8(v >> 16), B: uint8(v >> 8), A: uint8(v)}, nil
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"flag"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "regrava as imagens de referência em testdata")
+
+var cardData = CardData{
+	Name:    "agua",
+	Message: "Beba água!",
+	Time:    time.Date(2024, 10, 7, 9, 40, 0, 0, time.UTC),
+}
+
+func TestCardGolden(t *testing.T) {
+	// Ícone gerado aqui, para não depender de outro arquivo
+	iconPath := filepath.Join(t.TempDir(), "icone.png")
+	icon := image.NewNRGBA(image.Rect(0, 0, 32, 16))
+	for x := range 32 {
+		for y := range 16 {
+			icon.Set(x, y, color.NRGBA{R: uint8(x * 8), G: 128, B: uint8(y * 16), A: 255})
+		}
+	}
+	writePNG(t, iconPath, icon)
+
+	long := "Levante, alongue as costas, os ombros e o pescoço, encha a garrafa e volte antes que o café esfrie de novo"
+	tests := []struct {
+		golden string
+		tpl    CardTemplate
+		data   CardData
+	}{
+		{"padrao.png", DefaultCardTemplate(), cardData},
+		{"personalizado.png", CardTemplate{
+			Width: 320, Height: 120, Padding: 12, Radius: 0,
+			Background: "#1e3a5f", Foreground: "#fd0",
+			TitleSize: 20, BodySize: 14,
+			Title: "{{.Name}}", Body: "{{.Message}} ({{.Time.Format \"02/01 15:04\"}})",
+		}, cardData},
+		// Texto maior que o cartão: quebra em linhas e termina com reticências
+		{"reticencias.png", CardTemplate{Width: 300, Height: 110, Padding: 10, Title: "{{.Message}}"},
+			CardData{Name: "pausa", Message: long, Time: cardData.Time}},
+		{"icone.png", CardTemplate{Icon: iconPath, IconSize: 48, Padding: 16, Radius: 8}, cardData},
+	}
+	for _, tt := range tests {
+		t.Run(tt.golden, func(t *testing.T) {
+			r, err := NewCardRenderer(tt.tpl)
+			if err != nil {
+				t.Fatal(err)
+			}
+			img, err := r.Render(tt.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			path := filepath.Join("testdata", tt.golden)
+			if *update {
+				writePNG(t, path, img)
+				return
+			}
+			want := readPNG(t, path)
+			if err := sameImage(img, want); err != "" {
+				out := filepath.Join(t.TempDir(), tt.golden)
+				writePNG(t, out, img)
+				t.Errorf("%s (gerado em %s; rode com -update se a mudança for intencional)", err, out)
+			}
+		})
+	}
+}
+
+// sameImage compara pixel a pixel e descreve a primeira diferença.
+func sameImage(got, want image.Image) string {
+	if got.Bounds() != want.Bounds() {
+		return "tamanho " + got.Bounds().String() + ", quer " + want.Bounds().String()
+	}
+	b := got.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			g := color.NRGBAModel.Convert(got.At(x, y))
+			w := color.NRGBAModel.Convert(want.At(x, y))
+			if g != w {
+				return "pixel " + image.Pt(x, y).String() + " difere da referência"
+			}
+		}
+	}
+	return ""
+}
+
+func writePNG(t *testing.T, path string, img image.Image) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := png.Encode(f, img); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readPNG(t *testing.T, path string) image.Image {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	img, err := png.Decode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return img
+}
+
+func TestNewCardRendererInvalid(t *testing.T) {
+	tests := []CardTemplate{
+		{Width: -1},
+		{Padding: -1},
+		{Height: maxCardSize + 1},
+		{TitleSize: -1},
+		{BodySize: -1},
+		{IconSize: -1},
+		{Background: "azul"},
+		{Foreground: "#12345"},
+		{Title: "{{.Nome"},
+		{Body: "{{.Inexistente}}"},
+		{Font: "testdata/nao-existe.ttf"},
+		{Icon: "testdata/nao-existe.png"},
+	}
+	for _, tpl := range tests {
+		r, err := NewCardRenderer(tpl)
+		if err == nil {
+			// Campos inexistentes só falham ao executar o template
+			_, err = r.Render(cardData)
+		}
+		if err == nil {
+			t.Errorf("NewCardRenderer(%+v) aceitou", tpl)
+		}
+	}
+}
+
+// measure mede 10 pixels por letra, sem depender de fonte.
+func measure(s string) (float64, float64) {
+	return float64(len([]rune(s))) * 10, 10
+}
+
+func TestWrapText(t *testing.T) {
+	tests := []struct {
+		text  string
+		width float64
+		want  []string
+	}{
+		{"", 100, []string{""}},
+		{"beba água", 100, []string{"beba água"}},
+		{"beba água", 90, []string{"beba água"}},
+		{"beba água", 89, []string{"beba", "água"}},
+		{"  muitos   espaços  ", 200, []string{"muitos espaços"}},
+		{"um dois três quatro", 90, []string{"um dois", "três", "quatro"}},
+		{"linha\n\noutra", 100, []string{"linha", "", "outra"}},
+		// Palavra maior que a linha é partida entre letras
+		{"abcdefghij", 40, []string{"abcd", "efgh", "ij"}},
+		{"ok abcdefghij", 40, []string{"ok", "abcd", "efgh", "ij"}},
+		{"ação", 20, []string{"aç", "ão"}},
+		// Nem uma letra cabe: uma por linha, sem laço infinito
+		{"abc", 5, []string{"a", "b", "c"}},
+	}
+	for _, tt := range tests {
+		if got := wrapText(measure, tt.text, tt.width); !slices.Equal(got, tt.want) {
+			t.Errorf("wrapText(%q, %v) = %q, quer %q", tt.text, tt.width, got, tt.want)
+		}
+	}
+}
+
+func TestEllipsis(t *testing.T) {
+	tests := []struct {
+		line  string
+		width float64
+		want  string
+	}{
+		{"abc", 40, "abc…"},
+		{"abcdef", 40, "abc…"},
+		{"abc def", 50, "abc…"}, // o espaço antes das reticências é removido
+		{"água fria", 60, "água…"},
+		{"abc", 10, "…"},
+		{"abc", 0, "…"},
+		{"", 100, "…"},
+	}
+	for _, tt := range tests {
+		if got := ellipsis(measure, tt.line, tt.width); got != tt.want {
+			t.Errorf("ellipsis(%q, %v) = %q, quer %q", tt.line, tt.width, got, tt.want)
+		}
+	}
+}
+
+func TestParseColor(t *testing.T) {
+	tests := []struct {
+		s    string
+		want color.NRGBA
+	}{
+		{"#fff", color.NRGBA{255, 255, 255, 255}},
+		{"#1e3a5f", color.NRGBA{0x1e, 0x3a, 0x5f, 255}},
+		{"1e3a5f80", color.NRGBA{0x1e, 0x3a, 0x5f, 0x80}},
+	}
+	for _, tt := range tests {
+		got, err := parseColor(tt.s)
+		if err != nil || got != tt.want {
+			t.Errorf("parseColor(%q) = %v, %v; quer %v", tt.s, got, err, tt.want)
+		}
+	}
+	for _, s := range []string{"", "#ff", "#ggg", "#1234567"} {
+		if _, err := parseColor(s); err == nil {
+			t.Errorf("parseColor(%q) aceitou", s)
+		}
+	}
+}
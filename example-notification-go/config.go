@@ -0,0 +1,207 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config é o arquivo de lembretes. Card, se presente, muda a aparência do
+// cartão mostrado a cada disparo. Notifiers define canais de entrega
+// nomeados, além dos embutidos stdout, file e desktop; Notify lista os
+// canais dos lembretes que não escolhem nenhum.
+type Config struct {
+	Card      *CardTemplate             `yaml:"card"`
+	Notifiers map[string]NotifierConfig `yaml:"notifiers"`
+	Notify    []string                  `yaml:"notify"`
+	Reminders []ReminderConfig          `yaml:"reminders"`
+}
+
+// Tipos de canal de entrega.
+const (
+	NotifierStdout  = "stdout"  // uma linha na saída padrão
+	NotifierFile    = "file"    // cartão em PNG, apagado depois de clear_after
+	NotifierDesktop = "desktop" // notificação do desktop via D-Bus
+	NotifierWebhook = "webhook" // POST em JSON compatível com Slack e Teams
+)
+
+// NotifierConfig descreve um canal de entrega. Os campos usados dependem de Type.
+type NotifierConfig struct {
+	Type       string        `yaml:"type"`
+	Path       string        `yaml:"path"`        // file
+	ClearAfter time.Duration `yaml:"clear_after"` // file
+	URL        string        `yaml:"url"`         // webhook
+	Icon       string        `yaml:"icon"`        // desktop
+	Timeout    time.Duration `yaml:"timeout"`     // desktop: tempo na tela
+}
+
+// ReminderConfig descreve um lembrete no arquivo. Exatamente um entre Every
+// e Cron deve ser informado.
+// É também o formato dos lembretes na API e no armazenamento.
+type ReminderConfig struct {
+	Name    string        `yaml:"name" json:"name"`
+	Message string        `yaml:"message" json:"message"`
+	Every   Duration      `yaml:"every" json:"every,omitempty"`
+	Cron    string        `yaml:"cron" json:"cron,omitempty"`
+	Notify  []string      `yaml:"notify" json:"notify,omitempty"` // canais de entrega; padrão: os de Config.Notify
+	Active  *ActiveConfig `yaml:"active" json:"active,omitempty"`
+}
+
+// ActiveConfig é o horário ativo de um lembrete.
+type ActiveConfig struct {
+	Days  string `yaml:"days" json:"days,omitempty"`   // como o dia da semana do cron, ex.: "1-5"
+	Hours string `yaml:"hours" json:"hours,omitempty"` // ex.: "09:00-18:00"
+}
+
+// Duration é um time.Duration escrito como "40m" tanto em YAML quanto em JSON.
+type Duration time.Duration
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// DefaultConfig reproduz o comportamento original: beber água a cada 40s.
+func DefaultConfig() Config {
+	return Config{Reminders: []ReminderConfig{
+		{Name: "agua", Message: "Beba água!", Every: Duration(40 * time.Second)},
+	}}
+}
+
+// LoadConfig lê o arquivo YAML de lembretes. Diferente de DefaultConfig, não
+// completa nada: o arquivo precisa ter ao menos um lembrete, e chaves que o
+// Config não conhece, como "intervl" no lugar de "every", são erro. Os
+// tamanhos do cartão, se houver, passam por CardTemplate.Validate.
+func LoadConfig(path string) (Config, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Config{}, err
+	}
+	defer f.Close()
+
+	var cfg Config
+	dec := yaml.NewDecoder(f)
+	dec.KnownFields(true)
+	if err := dec.Decode(&cfg); err != nil {
+		return Config{}, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(cfg.Reminders) == 0 {
+		return Config{}, fmt.Errorf("%s: nenhum lembrete definido", path)
+	}
+	if cfg.Card != nil {
+		if err := cfg.Card.Validate(); err != nil {
+			return Config{}, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return cfg, nil
+}
+
+// Build converte a configuração nos lembretes usados pelo Scheduler.
+func (c Config) Build() ([]Reminder, error) {
+	reminders := make([]Reminder, 0, len(c.Reminders))
+	for i, rc := range c.Reminders {
+		r, err := rc.Reminder()
+		if err != nil {
+			return nil, fmt.Errorf("lembrete %d (%s): %w", i+1, rc.Name, err)
+		}
+		reminders = append(reminders, r)
+	}
+	return reminders, nil
+}
+
+// Reminder valida a configuração e a converte no lembrete do Scheduler.
+func (rc ReminderConfig) Reminder() (Reminder, error) {
+	if rc.Name == "" {
+		return Reminder{}, errors.New("name é obrigatório")
+	}
+	r := Reminder{Name: rc.Name, Message: rc.Message, Notify: rc.Notify}
+
+	switch {
+	case rc.Every != 0 && rc.Cron != "":
+		return Reminder{}, errors.New("use every ou cron, não os dois")
+	case rc.Every < 0:
+		return Reminder{}, errors.New("every deve ser maior que zero")
+	case rc.Every > 0:
+		r.Schedule = Every{Interval: time.Duration(rc.Every)}
+	case rc.Cron != "":
+		cron, err := ParseCron(rc.Cron)
+		if err != nil {
+			return Reminder{}, err
+		}
+		r.Schedule = cron
+	default:
+		return Reminder{}, errors.New("informe every ou cron")
+	}
+
+	if rc.Active != nil {
+		active, err := ParseActiveHours(rc.Active.Days, rc.Active.Hours)
+		if err != nil {
+			return Reminder{}, err
+		}
+		r.Active = active
+	}
+	return r, nil
+}
+
+// BuildNotifiers cria os canais embutidos e os da configuração, que podem
+// substituí-los. show é o tempo que o canal file embutido mantém o cartão.
+func (c Config) BuildNotifiers(show time.Duration) (map[string]Notifier, error) {
+	notifiers := map[string]Notifier{
+		NotifierStdout:  StdoutNotifier{W: os.Stdout},
+		NotifierFile:    &FileNotifier{Path: arquivoLembrete, ClearAfter: show},
+		NotifierDesktop: &DesktopNotifier{Timeout: int32(show / time.Millisecond)},
+	}
+	for name, nc := range c.Notifiers {
+		n, err := nc.notifier()
+		if err != nil {
+			return nil, fmt.Errorf("canal %q: %w", name, err)
+		}
+		notifiers[name] = n
+	}
+	return notifiers, nil
+}
+
+// DefaultNotify são os canais usados quando nem o lembrete nem a
+// configuração escolhem: a saída padrão e o cartão em arquivo.
+func (c Config) DefaultNotify() []string {
+	if len(c.Notify) > 0 {
+		return c.Notify
+	}
+	return []string{NotifierStdout, NotifierFile}
+}
+
+func (nc NotifierConfig) notifier() (Notifier, error) {
+	switch nc.Type {
+	case NotifierStdout:
+		return StdoutNotifier{W: os.Stdout}, nil
+	case NotifierFile:
+		if nc.Path == "" {
+			return nil, errors.New("path é obrigatório")
+		}
+		return &FileNotifier{Path: nc.Path, ClearAfter: nc.ClearAfter}, nil
+	case NotifierDesktop:
+		timeout := int32(-1)
+		if nc.Timeout > 0 {
+			timeout = int32(nc.Timeout / time.Millisecond)
+		}
+		return &DesktopNotifier{Icon: nc.Icon, Timeout: timeout}, nil
+	case NotifierWebhook:
+		if nc.URL == "" {
+			return nil, errors.New("url é obrigatório")
+		}
+		return NewWebhookNotifier(nc.URL), nil
+	default:
+		return nil, fmt.Errorf("tipo desconhecido %q (use %s, %s, %s ou %s)", nc.Type, NotifierStdout, NotifierFile, NotifierDesktop, NotifierWebhook)
+	}
+}
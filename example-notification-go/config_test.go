@@ -0,0 +1,35 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadConfig(t *testing.T) {
+	const reminder = "reminders:\n  - {name: agua, message: Beba água, every: 30m}\n"
+	tests := []struct {
+		name  string
+		yaml  string
+		fails bool
+	}{
+		{"lembrete", reminder, false},
+		{"cartão", reminder + "card: {width: 320, height: 120, icon_size: 0}\n", false},
+		{"sem lembretes", "reminders: []\n", true},
+		{"chave desconhecida", "reminders:\n  - {name: agua, message: oi, intervl: 30m}\n", true},
+		{"cartão largo demais", reminder + "card: {width: 100000}\n", true},
+		{"altura negativa", reminder + "card: {height: -1}\n", true},
+		{"fonte negativa", reminder + "card: {title_size: -4}\n", true},
+		{"corpo negativo", reminder + "card: {body_size: -4}\n", true},
+		{"ícone negativo", reminder + "card: {icon_size: -8}\n", true},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), "lembretes.yaml")
+		if err := os.WriteFile(path, []byte(tt.yaml), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadConfig(path); (err != nil) != tt.fails {
+			t.Errorf("%s: LoadConfig = %v, falha esperada: %v", tt.name, err, tt.fails)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// DesktopNotifier mostra notificações do desktop pelo serviço
+// org.freedesktop.Notifications do D-Bus de sessão (GNOME, KDE, XFCE...).
+type DesktopNotifier struct {
+	Icon    string // caminho de um ícone; vazio usa o padrão do sistema
+	Timeout int32  // em milissegundos; -1 deixa o servidor decidir
+}
+
+func (d *DesktopNotifier) Notify(ctx context.Context, n Notification) error {
+	conn, err := dbus.ConnectSessionBus(dbus.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	icon := d.Icon
+	if icon != "" {
+		if abs, err := filepath.Abs(icon); err == nil {
+			icon = abs
+		}
+	}
+	obj := conn.Object("org.freedesktop.Notifications", "/org/freedesktop/Notifications")
+	call := obj.CallWithContext(ctx, "org.freedesktop.Notifications.Notify", 0,
+		"example-notification-go", // nome do aplicativo
+		uint32(0),                 // não substitui outra notificação
+		icon,
+		n.Title,
+		n.Body,
+		[]string{},                // sem ações
+		map[string]dbus.Variant{}, // sem dicas
+		d.Timeout,
+	)
+	return call.Err
+}
@@ -0,0 +1,18 @@
+//go:build !linux
+
+package main
+
+import (
+	"context"
+	"errors"
+)
+
+// DesktopNotifier só está disponível no Linux, onde usa o D-Bus.
+type DesktopNotifier struct {
+	Icon    string
+	Timeout int32
+}
+
+func (d *DesktopNotifier) Notify(ctx context.Context, n Notification) error {
+	return errors.New("notificações do desktop disponíveis apenas no Linux")
+}
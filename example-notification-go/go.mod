@@ -2,9 +2,11 @@ module example_notification_go
 
 go 1.22.6
 
-require github.com/fogleman/gg v1.3.0
-
 require (
-	github.com/golang/freetype v0.0.0-20170609003504-e2365dfdc4a0 // indirect
-	golang.org/x/image v0.23.0 // indirect
+	github.com/fogleman/gg v1.3.0
+	github.com/golang/freetype v0.0.0-20170609003504-e2365dfdc4a0
+	golang.org/x/image v0.23.0
+	gopkg.in/yaml.v3 v3.0.1
 )
+
+require github.com/godbus/dbus/v5 v5.1.0
@@ -1,53 +1,216 @@
 package main
 
 import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
 	"fmt"
-	_ "image/color"
+	"io"
 	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 	"time"
-
-	"github.com/fogleman/gg"
 )
 
+const arquivoLembrete = "retangulo.png"
+
 func main() {
-	// Cria uma goroutine para controlar a exibição do retângulo
-	go func() {
-		for {
-			// Desenha o retângulo
-			desenharRetangulo()
+	configPath := flag.String("config", "", "arquivo YAML com os lembretes (padrão: beber água a cada 40s)")
+	show := flag.Duration("show", 10*time.Second, "tempo que o lembrete fica na tela")
+	poll := flag.Duration("poll", time.Second, "intervalo entre verificações da agenda")
+	storePath := flag.String("store", "lembretes.json", "arquivo JSON onde ficam os lembretes e as entregas (vazio não grava)")
+	addr := flag.String("addr", "127.0.0.1:8089", "endereço da API HTTP (vazio desativa)")
+	flag.Parse()
+	if *poll <= 0 {
+		fmt.Fprintln(os.Stderr, "Erro: -poll deve ser maior que zero")
+		os.Exit(2)
+	}
+
+	cfg := DefaultConfig()
+	if *configPath != "" {
+		var err error
+		if cfg, err = LoadConfig(*configPath); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	// Na primeira execução os lembretes vêm da configuração; depois, do
+	// arquivo do Store, que a API altera.
+	store, found, err := OpenStore(*storePath, time.Now)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if !found {
+		if _, err := cfg.Build(); err != nil {
+			log.Fatal(err)
+		}
+		if err := store.AddReminders(cfg.Reminders...); err != nil {
+			log.Fatal(err)
+		}
+	} else if *configPath != "" {
+		log.Printf("Usando os lembretes de %s; os de %s são ignorados", *storePath, *configPath)
+	}
+	stored := store.Reminders()
+	reminders := make([]Reminder, len(stored))
+	for i, sr := range stored {
+		if reminders[i], err = sr.Reminder(); err != nil {
+			log.Fatalf("%s: lembrete %q: %v", *storePath, sr.Name, err)
+		}
+	}
+	scheduler, err := NewScheduler(time.Now, reminders...)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, sr := range stored {
+		if sr.Paused {
+			scheduler.SetPaused(sr.Name, true)
+		}
+	}
+	card := DefaultCardTemplate()
+	if cfg.Card != nil {
+		card = *cfg.Card
+	}
+	renderer, err := NewCardRenderer(card)
+	if err != nil {
+		log.Fatal(err)
+	}
+	notifiers, err := cfg.BuildNotifiers(*show)
+	if err != nil {
+		log.Fatal(err)
+	}
+	// O prazo da entrega cobre todas as tentativas do webhook (até 12s).
+	dispatcher := &Dispatcher{Renderer: renderer, Notifiers: notifiers, Default: cfg.DefaultNotify(), Timeout: 15 * time.Second}
+	if err := dispatcher.Validate(reminders); err != nil {
+		log.Fatal(err)
+	}
 
-			// Aguarda 10 segundos
-			time.Sleep(10 * time.Second)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-			// Limpa a tela (ou redesenha a tela sem o retângulo)
-			limparTela()
+	if *addr != "" {
+		api := &API{Scheduler: scheduler, Store: store, Dispatcher: dispatcher}
+		server := &http.Server{Addr: *addr, Handler: api.Handler(), ReadHeaderTimeout: 5 * time.Second}
+		go func() {
+			<-ctx.Done()
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			server.Shutdown(shutdownCtx)
+		}()
+		go func() {
+			log.Printf("API em http://%s/reminders", *addr)
+			if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Printf("API: %v", err)
+			}
+		}()
+	}
 
-			// Aguarda 30 segundos
-			time.Sleep(30 * time.Second)
+	// Os comandos são lidos da entrada padrão; "sair" encerra o programa.
+	// Sem entrada (rodando em segundo plano) os lembretes continuam.
+	go func() {
+		if readCommands(os.Stdin, os.Stdout, scheduler) {
+			stop()
 		}
 	}()
+	listar(os.Stdout, scheduler)
 
-	// Aguarda indefinidamente
-	fmt.Scanln()
+	ticker := time.NewTicker(*poll)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			// Apaga os cartões ainda na tela.
+			for _, n := range notifiers {
+				if f, ok := n.(*FileNotifier); ok {
+					f.Clear()
+				}
+			}
+			return
+		case <-ticker.C:
+			for _, o := range scheduler.Due() {
+				d, err := store.AddDelivery(o)
+				if err != nil {
+					log.Printf("Erro ao registrar a entrega de %q: %v", o.Reminder.Name, err)
+				}
+				// A entrega roda à parte para um webhook lento não atrasar a agenda.
+				go func() {
+					err := dispatcher.Deliver(ctx, d.ID, o)
+					if err != nil {
+						log.Printf("Erro ao notificar %q: %v", o.Reminder.Name, err)
+					}
+					if err := store.FinishDelivery(d.ID, err); err != nil {
+						log.Printf("Erro ao registrar a entrega de %q: %v", o.Reminder.Name, err)
+					}
+				}()
+			}
+		}
+	}
 }
 
-func desenharRetangulo() {
-	const S = 1024
-	dc := gg.NewContext(S, S)
-	dc.SetRGBA(0, 0, 0, 0.5) // Define a cor com transparência (alfa = 0.5)
-	dc.DrawRectangle(100, 100, 400, 400)
-	dc.Fill()
-	dc.SetRGB(1, 1, 1)                                      // Define a cor do texto
-	dc.DrawStringAnchored("Beba água!", S/2, S/2, 0.5, 0.5) // Escreve o texto no centro do retângulo
-	err := dc.SavePNG("retangulo.png")
-	if err != nil {
-		log.Fatal(err)
+// readCommands interpreta os comandos digitados até "sair" ou o fim da
+// entrada. Retorna verdadeiro se o usuário pediu para sair.
+func readCommands(r io.Reader, w io.Writer, s *Scheduler) bool {
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		args := strings.Fields(sc.Text())
+		if len(args) == 0 {
+			continue
+		}
+
+		var status Status
+		var err error
+		switch {
+		case args[0] == "sair":
+			return true
+		case args[0] == "lista":
+			listar(w, s)
+			continue
+		case args[0] == "soneca" && (len(args) == 2 || len(args) == 3):
+			d := 10 * time.Minute
+			if len(args) == 3 {
+				if d, err = time.ParseDuration(args[2]); err != nil {
+					break
+				}
+			}
+			if err = checkSnooze(d); err != nil {
+				break
+			}
+			status, err = s.Snooze(args[1], d)
+		case args[0] == "pular" && len(args) == 2:
+			status, err = s.Skip(args[1])
+		default:
+			fmt.Fprintln(w, "Comandos: lista | soneca <nome> [duração] | pular <nome> | sair")
+			continue
+		}
+
+		if err != nil {
+			fmt.Fprintln(w, "Erro:", err)
+			continue
+		}
+		fmt.Fprintf(w, "%s: próximo em %s\n", status.Name, formatNext(status.Next))
 	}
-	fmt.Println("Desenho salvo em retangulo.png")
+	return false
 }
 
-func limparTela() {
-	// Implemente a lógica para limpar a tela aqui
-	// Pode ser algo como redesenhar a tela sem o retângulo,
-	// ou usar uma função específica da sua biblioteca gráfica
+func listar(w io.Writer, s *Scheduler) {
+	for _, st := range s.Upcoming() {
+		snoozed := ""
+		switch {
+		case st.Paused:
+			snoozed = " (pausado)"
+		case st.Snoozed:
+			snoozed = " (soneca)"
+		}
+		fmt.Fprintf(w, "%-12s %s%s  %s\n", st.Name, formatNext(st.Next), snoozed, st.Message)
+	}
+}
+
+func formatNext(t time.Time) string {
+	if t.IsZero() {
+		return "nunca"
+	}
+	return t.Format("02/01 15:04:05")
 }
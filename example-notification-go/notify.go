@@ -0,0 +1,257 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/fogleman/gg"
+)
+
+// Notification é um lembrete pronto para entrega: textos já com os
+// templates aplicados e o cartão desenhado. ID é o número da entrega, usado
+// para confirmá-la pela API; zero se ela não foi registrada.
+type Notification struct {
+	ID    int64
+	Name  string
+	Title string
+	Body  string
+	Time  time.Time
+	Card  image.Image
+}
+
+// Notifier entrega notificações por um canal.
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
+
+// StdoutNotifier escreve uma linha por notificação.
+type StdoutNotifier struct {
+	W io.Writer
+}
+
+func (s StdoutNotifier) Notify(ctx context.Context, n Notification) error {
+	line := fmt.Sprintf("[%s] %s: %s", n.Time.Format("15:04:05"), n.Name, n.Title)
+	if n.Body != "" {
+		line += " — " + n.Body
+	}
+	_, err := fmt.Fprintln(s.W, line)
+	return err
+}
+
+// FileNotifier grava o cartão em PNG e o apaga depois de ClearAfter (zero
+// mantém o arquivo). Um cartão novo substitui o anterior.
+type FileNotifier struct {
+	Path       string
+	ClearAfter time.Duration
+
+	mu    sync.Mutex
+	timer *time.Timer
+	card  int // conta os cartões gravados; cada timer só apaga o seu
+}
+
+func (f *FileNotifier) Notify(ctx context.Context, n Notification) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.timer != nil {
+		f.timer.Stop()
+	}
+	f.card++
+	if err := gg.SavePNG(f.Path, n.Card); err != nil {
+		return err
+	}
+	if f.ClearAfter > 0 {
+		card := f.card
+		f.timer = time.AfterFunc(f.ClearAfter, func() { f.expire(card) })
+	}
+	return nil
+}
+
+// expire apaga o cartão número card. Stop não impede um timer que já
+// disparou e espera por mu; se nesse meio tempo Notify gravou outro cartão,
+// este fica.
+func (f *FileNotifier) expire(card int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if card == f.card {
+		f.remove()
+	}
+}
+
+// Clear apaga o cartão, se ainda existir, e cancela o apagamento agendado.
+func (f *FileNotifier) Clear() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.timer != nil {
+		f.timer.Stop()
+	}
+	f.card++
+	f.remove()
+}
+
+func (f *FileNotifier) remove() {
+	if err := os.Remove(f.Path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.Print(err)
+	}
+}
+
+// WebhookPayload é o corpo enviado pelo WebhookNotifier. O campo text é o
+// que os webhooks de entrada do Slack e do Microsoft Teams exibem; os demais
+// ficam para integrações próprias.
+type WebhookPayload struct {
+	ID       int64     `json:"id,omitempty"` // para POST /deliveries/{id}/ack
+	Text     string    `json:"text"`
+	Reminder string    `json:"reminder"`
+	Title    string    `json:"title"`
+	Body     string    `json:"body,omitempty"`
+	Time     time.Time `json:"time"`
+}
+
+// WebhookNotifier envia a notificação em JSON por POST para URL. Falhas de
+// rede, tentativas que passam de AttemptTimeout e respostas 429 ou 5xx são
+// repetidas até Retries vezes, esperando RetryDelay a mais a cada tentativa;
+// as demais respostas de erro, não. O prazo do contexto vale para o total.
+type WebhookNotifier struct {
+	URL            string
+	Client         *http.Client
+	AttemptTimeout time.Duration
+	Retries        int
+	RetryDelay     time.Duration
+}
+
+// NewWebhookNotifier cria um WebhookNotifier com até duas repetições e
+// 3 segundos por tentativa: no pior caso, 3+1+3+2+3 = 12 segundos.
+func NewWebhookNotifier(url string) *WebhookNotifier {
+	return &WebhookNotifier{
+		URL:            url,
+		Client:         &http.Client{},
+		AttemptTimeout: 3 * time.Second,
+		Retries:        2,
+		RetryDelay:     time.Second,
+	}
+}
+
+func (w *WebhookNotifier) Notify(ctx context.Context, n Notification) error {
+	text := "*" + n.Title + "*"
+	if n.Body != "" {
+		text += "\n" + n.Body
+	}
+	body, err := json.Marshal(WebhookPayload{ID: n.ID, Text: text, Reminder: n.Name, Title: n.Title, Body: n.Body, Time: n.Time})
+	if err != nil {
+		return err
+	}
+
+	for attempt := 0; ; attempt++ {
+		retry, err := w.post(ctx, body)
+		if err == nil || !retry || attempt >= w.Retries || ctx.Err() != nil {
+			return err
+		}
+		log.Printf("falha no webhook (tentativa %d de %d): %v", attempt+1, w.Retries+1, err)
+
+		select {
+		case <-time.After(w.RetryDelay * time.Duration(attempt+1)):
+		case <-ctx.Done():
+			return err
+		}
+	}
+}
+
+// post envia o corpo uma vez. retry indica se vale a pena tentar de novo.
+func (w *WebhookNotifier) post(ctx context.Context, body []byte) (retry bool, err error) {
+	if w.AttemptTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, w.AttemptTimeout)
+		defer cancel()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.Client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		retry = resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+		return retry, fmt.Errorf("webhook %s respondeu %s", w.URL, resp.Status)
+	}
+	return false, nil
+}
+
+// Dispatcher monta a notificação de cada disparo e a entrega nos canais do
+// lembrete.
+type Dispatcher struct {
+	Renderer  *CardRenderer
+	Notifiers map[string]Notifier
+	Default   []string      // canais dos lembretes que não escolhem nenhum
+	Timeout   time.Duration // tempo máximo de cada entrega
+}
+
+// Validate verifica se todos os canais usados pelos lembretes existem.
+func (d *Dispatcher) Validate(reminders []Reminder) error {
+	check := func(names []string) error {
+		for _, name := range names {
+			if _, ok := d.Notifiers[name]; !ok {
+				return fmt.Errorf("canal de notificação desconhecido %q", name)
+			}
+		}
+		return nil
+	}
+	if err := check(d.Default); err != nil {
+		return err
+	}
+	for _, r := range reminders {
+		if err := check(r.Notify); err != nil {
+			return fmt.Errorf("lembrete %q: %w", r.Name, err)
+		}
+	}
+	return nil
+}
+
+// Deliver entrega o disparo em todos os canais do lembrete, em paralelo,
+// como a entrega número id. Retorna os erros de todos os canais que falharam.
+func (d *Dispatcher) Deliver(ctx context.Context, id int64, o Occurrence) error {
+	data := CardData{Name: o.Reminder.Name, Message: o.Reminder.Message, Time: o.At}
+	title, body, err := d.Renderer.Text(data)
+	if err != nil {
+		return err
+	}
+	n := Notification{ID: id, Name: o.Reminder.Name, Title: title, Body: body, Time: o.At, Card: d.Renderer.Draw(title, body)}
+
+	channels := o.Reminder.Notify
+	if len(channels) == 0 {
+		channels = d.Default
+	}
+	if d.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d.Timeout)
+		defer cancel()
+	}
+
+	errs := make([]error, len(channels))
+	var wg sync.WaitGroup
+	for i, name := range channels {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := d.Notifiers[name].Notify(ctx, n); err != nil {
+				errs[i] = fmt.Errorf("%s: %w", name, err)
+			}
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
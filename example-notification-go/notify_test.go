@@ -0,0 +1,239 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"image"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// webhook é um servidor de teste que responde com os status da lista, um por
+// requisição, e guarda os corpos recebidos. Esgotada a lista, responde 200.
+type webhook struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	statuses []int
+	bodies   []WebhookPayload
+}
+
+func newWebhook(t *testing.T, statuses ...int) *webhook {
+	t.Helper()
+	h := &webhook{statuses: statuses}
+	h.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("requisição %s com Content-Type %q", r.Method, r.Header.Get("Content-Type"))
+		}
+		var p WebhookPayload
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+			t.Errorf("corpo inválido: %v", err)
+		}
+
+		h.mu.Lock()
+		h.bodies = append(h.bodies, p)
+		status := http.StatusOK
+		if len(h.statuses) > 0 {
+			status, h.statuses = h.statuses[0], h.statuses[1:]
+		}
+		h.mu.Unlock()
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(h.Close)
+	return h
+}
+
+func (h *webhook) requests() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.bodies)
+}
+
+func testNotifier(url string) *WebhookNotifier {
+	n := NewWebhookNotifier(url)
+	n.RetryDelay = time.Millisecond
+	return n
+}
+
+var notification = Notification{
+	ID:    42,
+	Name:  "agua",
+	Title: "Beba água",
+	Body:  "Um copo agora",
+	Time:  time.Date(2024, 10, 7, 9, 40, 0, 0, time.UTC),
+}
+
+func TestWebhookNotify(t *testing.T) {
+	h := newWebhook(t)
+	if err := testNotifier(h.URL).Notify(context.Background(), notification); err != nil {
+		t.Fatal(err)
+	}
+
+	want := WebhookPayload{
+		ID:       42,
+		Text:     "*Beba água*\nUm copo agora",
+		Reminder: "agua",
+		Title:    "Beba água",
+		Body:     "Um copo agora",
+		Time:     notification.Time,
+	}
+	if len(h.bodies) != 1 || h.bodies[0] != want {
+		t.Errorf("corpos = %+v, quer %+v", h.bodies, want)
+	}
+}
+
+func TestWebhookNotifyRetry(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []int
+		requests int
+		fails    bool
+	}{
+		{"sucesso após 5xx", []int{http.StatusInternalServerError, http.StatusServiceUnavailable}, 3, false},
+		{"sucesso após 429", []int{http.StatusTooManyRequests}, 2, false},
+		{"tentativas esgotadas", []int{502, 502, 502, 502}, 3, true},
+		// Erros do cliente não mudam com a repetição
+		{"400 sem repetir", []int{http.StatusBadRequest}, 1, true},
+		{"404 sem repetir", []int{http.StatusNotFound}, 1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newWebhook(t, tt.statuses...)
+			err := testNotifier(h.URL).Notify(context.Background(), notification)
+			if (err != nil) != tt.fails {
+				t.Errorf("Notify = %v, falha esperada: %v", err, tt.fails)
+			}
+			if got := h.requests(); got != tt.requests {
+				t.Errorf("requisições = %d, quer %d", got, tt.requests)
+			}
+		})
+	}
+}
+
+func TestWebhookNotifyErrorMessage(t *testing.T) {
+	h := newWebhook(t, http.StatusForbidden)
+	err := testNotifier(h.URL).Notify(context.Background(), notification)
+	if err == nil || !strings.Contains(err.Error(), "403") {
+		t.Errorf("Notify = %v, quer erro com o status 403", err)
+	}
+}
+
+func TestWebhookNotifyUnreachable(t *testing.T) {
+	h := newWebhook(t)
+	url := h.URL
+	h.Close()
+
+	if err := testNotifier(url).Notify(context.Background(), notification); err == nil {
+		t.Error("Notify aceitou servidor fora do ar")
+	}
+}
+
+func TestWebhookNotifyCanceled(t *testing.T) {
+	h := newWebhook(t, 503, 503, 503)
+	n := testNotifier(h.URL)
+	n.RetryDelay = time.Hour
+
+	// O cancelamento interrompe a espera entre as tentativas
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := n.Notify(ctx, notification); err == nil {
+		t.Error("Notify aceitou com o contexto cancelado")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Notify levou %v após o cancelamento", elapsed)
+	}
+	if got := h.requests(); got != 1 {
+		t.Errorf("requisições = %d, quer 1", got)
+	}
+}
+
+func TestWebhookNotifyAttemptTimeout(t *testing.T) {
+	// A primeira tentativa fica presa até o cliente desistir; a segunda responde
+	var mu sync.Mutex
+	attempts := 0
+	// O servidor de teste não vê a desistência do cliente; release solta o
+	// handler preso antes de srv.Close, que espera por ele.
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		attempts++
+		first := attempts == 1
+		mu.Unlock()
+		if first {
+			<-release
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	n := testNotifier(srv.URL)
+	n.AttemptTimeout = 100 * time.Millisecond
+	// Como no Dispatcher: o prazo total é bem maior que o de uma tentativa
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := n.Notify(ctx, notification); err != nil {
+		t.Fatalf("Notify = %v, quer sucesso na segunda tentativa", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if attempts != 2 {
+		t.Errorf("tentativas = %d, quer 2", attempts)
+	}
+}
+
+func fileExists(t *testing.T, path string) bool {
+	t.Helper()
+	_, err := os.Stat(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		t.Fatal(err)
+	}
+	return err == nil
+}
+
+func TestFileNotifier(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cartao.png")
+	f := &FileNotifier{Path: path, ClearAfter: 50 * time.Millisecond}
+	card := notification
+	card.Card = image.NewRGBA(image.Rect(0, 0, 4, 4))
+
+	if err := f.Notify(context.Background(), card); err != nil {
+		t.Fatal(err)
+	}
+	if !fileExists(t, path) {
+		t.Fatal("cartão não gravado")
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for fileExists(t, path) {
+		if time.Now().After(deadline) {
+			t.Fatal("cartão não foi apagado depois de ClearAfter")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// O timer de um cartão antigo que dispara atrasado não apaga o novo
+	f.ClearAfter = time.Hour
+	if err := f.Notify(context.Background(), card); err != nil {
+		t.Fatal(err)
+	}
+	old := f.card
+	if err := f.Notify(context.Background(), card); err != nil {
+		t.Fatal(err)
+	}
+	f.expire(old)
+	if !fileExists(t, path) {
+		t.Error("timer antigo apagou o cartão novo")
+	}
+
+	f.Clear()
+	if fileExists(t, path) {
+		t.Error("Clear não apagou o cartão")
+	}
+}
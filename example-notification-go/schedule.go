@@ -0,0 +1,226 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule decide quando um lembrete dispara.
+type Schedule interface {
+	// Next retorna a primeira ocorrência estritamente depois de after, ou o
+	// tempo zero se não houver nenhuma.
+	Next(after time.Time) time.Time
+	// Resume retorna a primeira ocorrência a partir de t, inclusive. É usado
+	// quando o horário ativo começa.
+	Resume(t time.Time) time.Time
+}
+
+// Every dispara a cada Interval, contado a partir do último disparo.
+type Every struct {
+	Interval time.Duration
+}
+
+func (e Every) Next(after time.Time) time.Time { return after.Add(e.Interval) }
+
+// Resume dispara assim que o horário ativo começa.
+func (e Every) Resume(t time.Time) time.Time { return t }
+
+func (e Every) String() string { return "a cada " + e.Interval.String() }
+
+// Cron é uma expressão cron de cinco campos: minuto, hora, dia do mês, mês e
+// dia da semana (0 ou 7 é domingo). Cada campo aceita *, valores, intervalos
+// a-b, passos /n e listas separadas por vírgula.
+type Cron struct {
+	expr                          string
+	minute, hour, dom, month, dow uint64
+	domRestricted, dowRestricted  bool
+}
+
+// ParseCron interpreta uma expressão como "*/30 9-17 * * 1-5".
+func ParseCron(expr string) (*Cron, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron %q: esperados 5 campos, recebidos %d", expr, len(fields))
+	}
+
+	c := &Cron{expr: expr}
+	var err error
+	parse := func(field string, min, max int) uint64 {
+		if err != nil {
+			return 0
+		}
+		var bits uint64
+		bits, err = parseCronField(field, min, max)
+		if err != nil {
+			err = fmt.Errorf("cron %q: campo %q: %w", expr, field, err)
+		}
+		return bits
+	}
+	c.minute = parse(fields[0], 0, 59)
+	c.hour = parse(fields[1], 0, 23)
+	c.dom = parse(fields[2], 1, 31)
+	c.month = parse(fields[3], 1, 12)
+	c.dow = parse(fields[4], 0, 7)
+	if err != nil {
+		return nil, err
+	}
+	if c.dow&(1<<7) != 0 {
+		c.dow |= 1 // 7 também é domingo
+	}
+	c.domRestricted = fields[2] != "*"
+	c.dowRestricted = fields[4] != "*"
+	return c, nil
+}
+
+// parseCronField converte um campo em um conjunto de bits, um por valor.
+func parseCronField(field string, min, max int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rng, stepText, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepText)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("passo inválido %q", stepText)
+			}
+			step = n
+		}
+
+		lo, hi := min, max
+		if rng != "*" {
+			first, last, isRange := strings.Cut(rng, "-")
+			var err error
+			if lo, err = strconv.Atoi(first); err != nil {
+				return 0, fmt.Errorf("valor inválido %q", first)
+			}
+			hi = lo
+			if isRange {
+				if hi, err = strconv.Atoi(last); err != nil {
+					return 0, fmt.Errorf("valor inválido %q", last)
+				}
+			} else if hasStep {
+				hi = max // "5/15" vai de 5 até o fim
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return 0, fmt.Errorf("%q fora do intervalo %d-%d", part, min, max)
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << v
+		}
+	}
+	return bits, nil
+}
+
+func (c *Cron) String() string { return c.expr }
+
+// Next procura minuto a minuto, pulando meses, dias e horas que não casam.
+// Procura no máximo cinco anos à frente, o que cobre expressões como 29/2.
+func (c *Cron) Next(after time.Time) time.Time {
+	loc := after.Location()
+	t := time.Date(after.Year(), after.Month(), after.Day(), after.Hour(), after.Minute(), 0, 0, loc).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		switch {
+		case c.month&(1<<t.Month()) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+		case !c.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+		case c.hour&(1<<t.Hour()) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+		case c.minute&(1<<t.Minute()) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// dayMatches segue o cron tradicional: se dia do mês e dia da semana forem
+// ambos restritos, basta um deles casar.
+func (c *Cron) dayMatches(t time.Time) bool {
+	dom := c.dom&(1<<t.Day()) != 0
+	dow := c.dow&(1<<t.Weekday()) != 0
+	if c.domRestricted && c.dowRestricted {
+		return dom || dow
+	}
+	return dom && dow
+}
+
+func (c *Cron) Resume(t time.Time) time.Time { return c.Next(t.Add(-time.Nanosecond)) }
+
+// ActiveHours limita os disparos a alguns dias da semana e a um horário,
+// como "1-5" e "09:00-18:00" para dias úteis em horário comercial.
+type ActiveHours struct {
+	days     uint64 // bit 0 é domingo
+	from, to int    // minutos desde a meia-noite; to é exclusivo
+}
+
+// ParseActiveHours interpreta os dias no formato do campo dia da semana do
+// cron (vazio é todos) e o horário como "HH:MM-HH:MM" (vazio é o dia todo).
+func ParseActiveHours(days, hours string) (*ActiveHours, error) {
+	a := &ActiveHours{days: 0x7f, from: 0, to: 24 * 60}
+	if days != "" {
+		bits, err := parseCronField(days, 0, 7)
+		if err != nil {
+			return nil, fmt.Errorf("dias %q: %w", days, err)
+		}
+		if bits&(1<<7) != 0 {
+			bits |= 1
+		}
+		a.days = bits & 0x7f
+	}
+	if hours != "" {
+		from, to, ok := strings.Cut(hours, "-")
+		if !ok {
+			return nil, fmt.Errorf("horário %q: use HH:MM-HH:MM", hours)
+		}
+		var err error
+		if a.from, err = parseClock(from); err != nil {
+			return nil, fmt.Errorf("horário %q: %w", hours, err)
+		}
+		if a.to, err = parseClock(to); err != nil {
+			return nil, fmt.Errorf("horário %q: %w", hours, err)
+		}
+		if a.from >= a.to {
+			return nil, fmt.Errorf("horário %q: o início deve ser antes do fim", hours)
+		}
+	}
+	return a, nil
+}
+
+// parseClock converte "HH:MM" em minutos desde a meia-noite. "24:00" é
+// aceito como fim do dia.
+func parseClock(s string) (int, error) {
+	s = strings.TrimSpace(s)
+	if s == "24:00" {
+		return 24 * 60, nil
+	}
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("hora inválida %q", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// Contains informa se t está dentro do horário ativo.
+func (a *ActiveHours) Contains(t time.Time) bool {
+	m := t.Hour()*60 + t.Minute()
+	return a.days&(1<<t.Weekday()) != 0 && m >= a.from && m < a.to
+}
+
+// NextOpen retorna o próximo início do horário ativo a partir de t, ou o
+// tempo zero se nenhum dia estiver ativo.
+func (a *ActiveHours) NextOpen(t time.Time) time.Time {
+	for i := range 8 {
+		open := time.Date(t.Year(), t.Month(), t.Day()+i, a.from/60, a.from%60, 0, 0, t.Location())
+		if a.days&(1<<open.Weekday()) != 0 && !open.Before(t) {
+			return open
+		}
+	}
+	return time.Time{}
+}
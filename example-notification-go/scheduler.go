@@ -0,0 +1,257 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Reminder é um lembrete com nome, mensagem e agenda. Active, se definido,
+// restringe os disparos ao horário ativo. Notify lista os canais de entrega;
+// vazio usa os canais padrão do Dispatcher.
+type Reminder struct {
+	Name     string
+	Message  string
+	Schedule Schedule
+	Active   *ActiveHours
+	Notify   []string
+}
+
+// after retorna o próximo disparo depois de t, empurrado para o início do
+// horário ativo quando cair fora dele. O tempo zero indica que não há mais
+// disparos.
+func (r Reminder) after(t time.Time) time.Time {
+	next := r.Schedule.Next(t)
+	for range 1000 {
+		if next.IsZero() || r.Active == nil || r.Active.Contains(next) {
+			return next
+		}
+		open := r.Active.NextOpen(next)
+		if open.IsZero() {
+			return open
+		}
+		next = r.Schedule.Resume(open)
+	}
+	return time.Time{}
+}
+
+// Occurrence é um disparo de lembrete. At é o horário agendado, que pode ser
+// anterior ao atual se o programa ficou parado (por exemplo, com o
+// computador suspenso).
+type Occurrence struct {
+	Reminder Reminder
+	At       time.Time
+	Snoozed  bool
+}
+
+// Status descreve o próximo disparo de um lembrete.
+type Status struct {
+	Name    string    `json:"name"`
+	Message string    `json:"message"`
+	Next    time.Time `json:"next"` // zero se não houver próximo disparo
+	Snoozed bool      `json:"snoozed"`
+	Paused  bool      `json:"paused"`
+}
+
+type entry struct {
+	reminder Reminder
+	next     time.Time
+	snoozed  bool
+	paused   bool
+}
+
+// Scheduler guarda o próximo disparo de cada lembrete. Quem o usa chama Due
+// a cada poucos segundos; a hora atual vem sempre de Now, nunca de time.Now.
+type Scheduler struct {
+	Now func() time.Time
+
+	mu      sync.Mutex
+	entries []*entry
+}
+
+// NewScheduler agenda o primeiro disparo de cada lembrete a partir de agora.
+func NewScheduler(now func() time.Time, reminders ...Reminder) (*Scheduler, error) {
+	s := &Scheduler{Now: now}
+	start := now()
+	seen := make(map[string]bool)
+	for _, r := range reminders {
+		if seen[r.Name] {
+			return nil, fmt.Errorf("lembrete %q repetido", r.Name)
+		}
+		seen[r.Name] = true
+		s.entries = append(s.entries, &entry{reminder: r, next: r.after(start)})
+	}
+	return s, nil
+}
+
+// Due retorna os lembretes cujo disparo já chegou e agenda o próximo de
+// cada um. Disparos perdidos enquanto o programa estava parado viram um só.
+func (s *Scheduler) Due() []Occurrence {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.Now()
+	var due []Occurrence
+	for _, e := range s.entries {
+		if e.paused || e.next.IsZero() || e.next.After(now) {
+			continue
+		}
+		due = append(due, Occurrence{Reminder: e.reminder, At: e.next, Snoozed: e.snoozed})
+		e.next = e.reminder.after(now)
+		e.snoozed = false
+	}
+	return due
+}
+
+// Snooze adia o próximo disparo do lembrete para daqui a d, mesmo fora do
+// horário ativo. Depois dele a agenda normal continua.
+func (s *Scheduler) Snooze(name string, d time.Duration) (Status, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, err := s.find(name)
+	if err != nil {
+		return Status{}, err
+	}
+	e.next = s.Now().Add(d)
+	e.snoozed = true
+	return e.status(), nil
+}
+
+// checkSnooze confere a duração de uma soneca pedida pela API ou pelo
+// terminal.
+func checkSnooze(d time.Duration) error {
+	if d <= 0 {
+		return errors.New("a duração da soneca deve ser maior que zero")
+	}
+	return nil
+}
+
+// Skip pula o próximo disparo do lembrete. Um adiamento pendente também é
+// descartado.
+func (s *Scheduler) Skip(name string) (Status, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, err := s.find(name)
+	if err != nil {
+		return Status{}, err
+	}
+	if e.snoozed {
+		e.next = e.reminder.after(s.Now())
+		e.snoozed = false
+	} else if !e.next.IsZero() {
+		e.next = e.reminder.after(e.next)
+	}
+	return e.status(), nil
+}
+
+// Add agenda um lembrete novo a partir de agora.
+func (s *Scheduler) Add(r Reminder) (Status, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.find(r.Name); err == nil {
+		return Status{}, fmt.Errorf("lembrete %q %w", r.Name, ErrExists)
+	}
+	e := &entry{reminder: r, next: r.after(s.Now())}
+	s.entries = append(s.entries, e)
+	return e.status(), nil
+}
+
+// Update troca a agenda, a mensagem e os canais de um lembrete existente e
+// recalcula o próximo disparo. Um adiamento pendente é descartado; a pausa
+// é mantida.
+func (s *Scheduler) Update(r Reminder) (Status, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, err := s.find(r.Name)
+	if err != nil {
+		return Status{}, err
+	}
+	e.reminder = r
+	e.next = r.after(s.Now())
+	e.snoozed = false
+	return e.status(), nil
+}
+
+// Remove tira o lembrete da agenda.
+func (s *Scheduler) Remove(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, e := range s.entries {
+		if e.reminder.Name == name {
+			s.entries = append(s.entries[:i], s.entries[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("lembrete %q %w", name, ErrNotFound)
+}
+
+// SetPaused pausa ou retoma um lembrete. Pausado, ele não dispara; ao ser
+// retomado, o próximo disparo é calculado a partir de agora, sem repor os
+// perdidos.
+func (s *Scheduler) SetPaused(name string, paused bool) (Status, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, err := s.find(name)
+	if err != nil {
+		return Status{}, err
+	}
+	if e.paused && !paused {
+		e.next = e.reminder.after(s.Now())
+		e.snoozed = false
+	}
+	e.paused = paused
+	return e.status(), nil
+}
+
+// Status retorna o estado de um lembrete.
+func (s *Scheduler) Status(name string) (Status, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, err := s.find(name)
+	if err != nil {
+		return Status{}, err
+	}
+	return e.status(), nil
+}
+
+// Upcoming lista os lembretes pelo próximo disparo; os sem disparo ficam
+// no fim.
+func (s *Scheduler) Upcoming() []Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list := make([]Status, len(s.entries))
+	for i, e := range s.entries {
+		list[i] = e.status()
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		a, b := list[i].Next, list[j].Next
+		if a.IsZero() || b.IsZero() {
+			return !a.IsZero() && b.IsZero()
+		}
+		return a.Before(b)
+	})
+	return list
+}
+
+func (s *Scheduler) find(name string) (*entry, error) {
+	for _, e := range s.entries {
+		if e.reminder.Name == name {
+			return e, nil
+		}
+	}
+	return nil, fmt.Errorf("lembrete %q %w", name, ErrNotFound)
+}
+
+func (e *entry) status() Status {
+	return Status{Name: e.reminder.Name, Message: e.reminder.Message, Next: e.next, Snoozed: e.snoozed, Paused: e.paused}
+}
@@ -0,0 +1,331 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// FakeClock é um relógio controlado manualmente.
+type FakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewFakeClock cria um relógio parado em start.
+func NewFakeClock(start time.Time) *FakeClock {
+	return &FakeClock{now: start}
+}
+
+// Now retorna o horário atual do relógio.
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Advance avança o relógio em d.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// Set move o relógio para t.
+func (c *FakeClock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = t
+}
+
+// day retorna o horário hh:mm do dia d de outubro de 2024; o dia 7 é uma
+// segunda-feira.
+func day(d, hh, mm int) time.Time {
+	return time.Date(2024, time.October, d, hh, mm, 0, 0, time.UTC)
+}
+
+func mustCron(t *testing.T, expr string) *Cron {
+	t.Helper()
+	c, err := ParseCron(expr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func mustActive(t *testing.T, days, hours string) *ActiveHours {
+	t.Helper()
+	a, err := ParseActiveHours(days, hours)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+func mustScheduler(t *testing.T, clock *FakeClock, reminders ...Reminder) *Scheduler {
+	t.Helper()
+	s, err := NewScheduler(clock.Now, reminders...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// dueAt avança o relógio até at e retorna os lembretes disparados.
+func dueAt(s *Scheduler, clock *FakeClock, at time.Time) []Occurrence {
+	clock.Set(at)
+	return s.Due()
+}
+
+func TestSchedulerEvery(t *testing.T) {
+	clock := NewFakeClock(day(7, 9, 0))
+	s := mustScheduler(t, clock, Reminder{Name: "agua", Schedule: Every{Interval: 40 * time.Minute}})
+
+	if due := dueAt(s, clock, day(7, 9, 39)); len(due) != 0 {
+		t.Fatalf("disparou antes do intervalo: %+v", due)
+	}
+	due := dueAt(s, clock, day(7, 9, 40))
+	if len(due) != 1 || due[0].Reminder.Name != "agua" || !due[0].At.Equal(day(7, 9, 40)) || due[0].Snoozed {
+		t.Fatalf("Due() = %+v", due)
+	}
+
+	// O intervalo conta a partir do disparo
+	if st, _ := s.Status("agua"); !st.Next.Equal(day(7, 10, 20)) {
+		t.Errorf("próximo = %v, quer 10:20", st.Next)
+	}
+
+	// Depois de horas parado (computador suspenso) dispara uma vez só
+	due = dueAt(s, clock, day(7, 15, 0))
+	if len(due) != 1 || !due[0].At.Equal(day(7, 10, 20)) {
+		t.Fatalf("Due() após suspensão = %+v", due)
+	}
+	if st, _ := s.Status("agua"); !st.Next.Equal(day(7, 15, 40)) {
+		t.Errorf("próximo = %v, quer 15:40", st.Next)
+	}
+}
+
+func TestCronNext(t *testing.T) {
+	tests := []struct {
+		expr  string
+		after time.Time
+		want  time.Time
+	}{
+		{"*/30 9-17 * * 1-5", day(7, 9, 0), day(7, 9, 30)},
+		{"*/30 9-17 * * 1-5", day(7, 9, 29), day(7, 9, 30)},
+		{"*/30 9-17 * * 1-5", day(7, 17, 30), day(8, 9, 0)},
+		{"*/30 9-17 * * 1-5", day(11, 18, 0), day(14, 9, 0)}, // sexta à noite: segunda
+		{"0 9,18 * * 1-5", day(7, 9, 0), day(7, 18, 0)},
+		{"0 9 * * 0", day(7, 9, 0), day(13, 9, 0)},
+		{"0 9 * * 7", day(7, 9, 0), day(13, 9, 0)}, // 7 também é domingo
+		{"15 10 1 * *", day(7, 9, 0), time.Date(2024, 11, 1, 10, 15, 0, 0, time.UTC)},
+		{"0 12 29 2 *", day(7, 9, 0), time.Date(2028, 2, 29, 12, 0, 0, 0, time.UTC)},
+		// Dia do mês e da semana restritos: basta um casar
+		{"0 8 10 * 1", day(8, 9, 0), day(10, 8, 0)},
+		{"0 8 20 * 1", day(8, 9, 0), day(14, 8, 0)},
+		{"5/20 * * * *", day(7, 9, 45), day(7, 10, 5)},
+		// Segundos não contam: o próximo é sempre um minuto cheio depois
+		{"* * * * *", day(7, 9, 0).Add(59 * time.Second), day(7, 9, 1)},
+		// Nunca acontece (31 de fevereiro)
+		{"0 0 31 2 *", day(7, 9, 0), time.Time{}},
+	}
+	for _, tt := range tests {
+		if got := mustCron(t, tt.expr).Next(tt.after); !got.Equal(tt.want) {
+			t.Errorf("Cron(%q).Next(%v) = %v, quer %v", tt.expr, tt.after, got, tt.want)
+		}
+	}
+}
+
+func TestParseCronInvalid(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+	} {
+		if _, err := ParseCron(expr); err == nil {
+			t.Errorf("ParseCron(%q) aceitou", expr)
+		}
+	}
+}
+
+func TestSchedulerActiveHours(t *testing.T) {
+	// Sexta às 17:30, horário ativo em dias úteis das 9h às 18h
+	clock := NewFakeClock(day(11, 17, 30))
+	active := mustActive(t, "1-5", "09:00-18:00")
+	s := mustScheduler(t, clock,
+		Reminder{Name: "agua", Schedule: Every{Interval: 40 * time.Minute}, Active: active},
+		Reminder{Name: "postura", Schedule: mustCron(t, "*/30 * * * *"), Active: active},
+	)
+
+	// 18:10 e 18:00 caem fora do horário: os dois vão para segunda às 9h
+	for _, name := range []string{"agua", "postura"} {
+		st, err := s.Status(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !st.Next.Equal(day(14, 9, 0)) {
+			t.Errorf("%s: próximo = %v, quer segunda às 9h", name, st.Next)
+		}
+	}
+
+	if due := dueAt(s, clock, day(13, 23, 59)); len(due) != 0 {
+		t.Fatalf("disparou no fim de semana: %+v", due)
+	}
+	if due := dueAt(s, clock, day(14, 9, 0)); len(due) != 2 {
+		t.Fatalf("Due() na segunda = %+v", due)
+	}
+}
+
+func TestActiveHours(t *testing.T) {
+	a := mustActive(t, "1-5", "09:00-18:00")
+	tests := []struct {
+		at       time.Time
+		contains bool
+		open     time.Time
+	}{
+		{day(7, 9, 0), true, day(7, 9, 0)},
+		{day(7, 17, 59), true, day(8, 9, 0)},
+		{day(7, 18, 0), false, day(8, 9, 0)},
+		{day(7, 8, 59), false, day(7, 9, 0)},
+		{day(12, 10, 0), false, day(14, 9, 0)}, // sábado
+	}
+	for _, tt := range tests {
+		if got := a.Contains(tt.at); got != tt.contains {
+			t.Errorf("Contains(%v) = %v", tt.at, got)
+		}
+		if got := a.NextOpen(tt.at); !got.Equal(tt.open) {
+			t.Errorf("NextOpen(%v) = %v, quer %v", tt.at, got, tt.open)
+		}
+	}
+
+	all := mustActive(t, "", "22:00-24:00")
+	if !all.Contains(day(12, 23, 59)) || all.Contains(day(12, 21, 59)) {
+		t.Error("22:00-24:00 em todos os dias")
+	}
+	for _, hours := range []string{"9-18", "18:00-09:00", "09:00-09:00", "25:00-26:00"} {
+		if _, err := ParseActiveHours("", hours); err == nil {
+			t.Errorf("ParseActiveHours(%q) aceitou", hours)
+		}
+	}
+}
+
+func TestSchedulerSnooze(t *testing.T) {
+	clock := NewFakeClock(day(7, 9, 0))
+	active := mustActive(t, "1-5", "09:00-18:00")
+	s := mustScheduler(t, clock, Reminder{Name: "agua", Schedule: Every{Interval: time.Hour}, Active: active})
+
+	// Adiado para fora do horário ativo: o adiamento vale mesmo assim
+	clock.Set(day(7, 17, 50))
+	st, err := s.Snooze("agua", 20*time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !st.Snoozed || !st.Next.Equal(day(7, 18, 10)) {
+		t.Fatalf("Snooze = %+v", st)
+	}
+
+	due := dueAt(s, clock, day(7, 18, 10))
+	if len(due) != 1 || !due[0].Snoozed {
+		t.Fatalf("Due() = %+v, quer o disparo adiado", due)
+	}
+	// Depois dele, a agenda normal continua no próximo horário ativo
+	if st, _ := s.Status("agua"); st.Snoozed || !st.Next.Equal(day(8, 9, 0)) {
+		t.Errorf("status = %+v, quer terça às 9h", st)
+	}
+
+	if _, err := s.Snooze("nada", time.Minute); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Snooze(nada) = %v, quer ErrNotFound", err)
+	}
+}
+
+func TestReadCommandsSnooze(t *testing.T) {
+	clock := NewFakeClock(day(7, 9, 0))
+	s := mustScheduler(t, clock, Reminder{Name: "agua", Schedule: Every{Interval: time.Hour}})
+
+	// Como na API, a soneca precisa de uma duração positiva
+	var out strings.Builder
+	in := "soneca agua -5m\nsoneca agua 0s\nsoneca agua 5m\nsair\n"
+	if !readCommands(strings.NewReader(in), &out, s) {
+		t.Fatal("readCommands não terminou com sair")
+	}
+	want := "Erro: a duração da soneca deve ser maior que zero\n" +
+		"Erro: a duração da soneca deve ser maior que zero\n" +
+		"agua: próximo em 07/10 09:05:00\n"
+	if out.String() != want {
+		t.Errorf("saída =\n%s\nquer\n%s", out.String(), want)
+	}
+}
+
+func TestSchedulerSkip(t *testing.T) {
+	clock := NewFakeClock(day(7, 9, 0))
+	s := mustScheduler(t, clock, Reminder{Name: "ponto", Schedule: mustCron(t, "0 9,18 * * 1-5")})
+
+	st, err := s.Skip("ponto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !st.Next.Equal(day(8, 9, 0)) {
+		t.Errorf("após Skip, próximo = %v, quer terça às 9h", st.Next)
+	}
+
+	// Pular um adiamento volta para a agenda normal
+	s.Snooze("ponto", 5*time.Minute)
+	if st, _ := s.Skip("ponto"); st.Snoozed || !st.Next.Equal(day(7, 18, 0)) {
+		t.Errorf("após Skip do adiamento = %+v, quer 18h", st)
+	}
+}
+
+func TestSchedulerPause(t *testing.T) {
+	clock := NewFakeClock(day(7, 9, 0))
+	s := mustScheduler(t, clock, Reminder{Name: "agua", Schedule: Every{Interval: 10 * time.Minute}})
+
+	if st, _ := s.SetPaused("agua", true); !st.Paused {
+		t.Fatalf("status = %+v", st)
+	}
+	if due := dueAt(s, clock, day(7, 10, 0)); len(due) != 0 {
+		t.Fatalf("pausado disparou: %+v", due)
+	}
+	// Ao retomar, não repõe os disparos perdidos
+	st, _ := s.SetPaused("agua", false)
+	if st.Paused || !st.Next.Equal(day(7, 10, 10)) {
+		t.Errorf("status = %+v, quer próximo às 10:10", st)
+	}
+}
+
+func TestSchedulerAddUpdateRemove(t *testing.T) {
+	clock := NewFakeClock(day(7, 9, 0))
+	s := mustScheduler(t, clock, Reminder{Name: "agua", Schedule: Every{Interval: time.Hour}})
+
+	if _, err := s.Add(Reminder{Name: "agua", Schedule: Every{Interval: time.Minute}}); !errors.Is(err, ErrExists) {
+		t.Errorf("Add repetido = %v, quer ErrExists", err)
+	}
+	if _, err := s.Add(Reminder{Name: "postura", Schedule: Every{Interval: 30 * time.Minute}}); err != nil {
+		t.Fatal(err)
+	}
+	clock.Set(day(7, 9, 10))
+	if st, _ := s.Update(Reminder{Name: "agua", Schedule: Every{Interval: 5 * time.Minute}}); !st.Next.Equal(day(7, 9, 15)) {
+		t.Errorf("Update: próximo = %v, quer 9:15", st.Next)
+	}
+	if up := s.Upcoming(); len(up) != 2 || up[0].Name != "agua" || up[1].Name != "postura" {
+		t.Errorf("Upcoming() = %+v", up)
+	}
+	if err := s.Remove("agua"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Remove("agua"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Remove repetido = %v, quer ErrNotFound", err)
+	}
+
+	if _, err := NewScheduler(clock.Now, Reminder{Name: "x", Schedule: Every{Interval: time.Hour}}, Reminder{Name: "x", Schedule: Every{Interval: time.Hour}}); err == nil {
+		t.Error("NewScheduler aceitou nomes repetidos")
+	}
+}
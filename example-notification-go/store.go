@@ -0,0 +1,314 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+// maxDeliveries é quantas entregas o Store guarda; as mais antigas saem
+// primeiro.
+const maxDeliveries = 1000
+
+// Erros do Store e do Scheduler que a API responde com 404 e 409.
+var (
+	ErrNotFound = errors.New("não existe")
+	ErrExists   = errors.New("já existe")
+)
+
+// StoredReminder é um lembrete guardado no Store.
+type StoredReminder struct {
+	ReminderConfig
+	Paused bool `json:"paused,omitempty"`
+}
+
+// Delivery registra um disparo e se ele foi confirmado por quem o recebeu.
+type Delivery struct {
+	ID          int64      `json:"id"`
+	Reminder    string     `json:"reminder"`
+	At          time.Time  `json:"at"` // horário agendado
+	Snoozed     bool       `json:"snoozed,omitempty"`
+	DeliveredAt *time.Time `json:"delivered_at,omitempty"`
+	Error       string     `json:"error,omitempty"`
+	AckedAt     *time.Time `json:"acked_at,omitempty"`
+}
+
+// StoreData é o conteúdo do arquivo do Store.
+type StoreData struct {
+	Reminders  []StoredReminder `json:"reminders"`
+	Deliveries []Delivery       `json:"deliveries"`
+}
+
+// Store guarda os lembretes e o histórico de entregas em um arquivo JSON,
+// regravado por inteiro a cada alteração. Com Path vazio nada é gravado.
+type Store struct {
+	Path string
+	Now  func() time.Time
+
+	mu     sync.Mutex
+	data   StoreData
+	nextID int64
+}
+
+// OpenStore lê o arquivo path. Se ele não existir, o Store começa vazio e
+// found é falso.
+func OpenStore(path string, now func() time.Time) (s *Store, found bool, err error) {
+	s = &Store{Path: path, Now: now, nextID: 1}
+	if path == "" {
+		return s, false, nil
+	}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	if err := json.Unmarshal(b, &s.data); err != nil {
+		return nil, false, fmt.Errorf("%s: %w", path, err)
+	}
+	for _, d := range s.data.Deliveries {
+		s.nextID = max(s.nextID, d.ID+1)
+	}
+	return s, true, nil
+}
+
+// Reminders retorna uma cópia dos lembretes guardados.
+func (s *Store) Reminders() []StoredReminder {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]StoredReminder(nil), s.data.Reminders...)
+}
+
+// Reminder retorna o lembrete chamado name.
+func (s *Store) Reminder(name string) (StoredReminder, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.index(name)
+	if i < 0 {
+		return StoredReminder{}, fmt.Errorf("lembrete %q %w", name, ErrNotFound)
+	}
+	return s.data.Reminders[i], nil
+}
+
+// AddReminders guarda lembretes novos. Nenhum é guardado se algum nome já
+// existir ou se repetir entre eles.
+func (s *Store) AddReminders(rcs ...ReminderConfig) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	seen := make(map[string]bool, len(rcs))
+	for _, rc := range rcs {
+		if s.index(rc.Name) >= 0 || seen[rc.Name] {
+			return fmt.Errorf("lembrete %q %w", rc.Name, ErrExists)
+		}
+		seen[rc.Name] = true
+	}
+	return s.update(func(data *StoreData) {
+		for _, rc := range rcs {
+			data.Reminders = append(data.Reminders, StoredReminder{ReminderConfig: rc})
+		}
+	})
+}
+
+// UpdateReminder substitui a configuração de um lembrete, mantendo a pausa.
+func (s *Store) UpdateReminder(rc ReminderConfig) (StoredReminder, error) {
+	return s.modify(rc.Name, func(sr *StoredReminder) { sr.ReminderConfig = rc })
+}
+
+// SetPaused marca o lembrete como pausado ou ativo.
+func (s *Store) SetPaused(name string, paused bool) (StoredReminder, error) {
+	return s.modify(name, func(sr *StoredReminder) { sr.Paused = paused })
+}
+
+// DeleteReminder apaga o lembrete. As entregas dele continuam no histórico.
+func (s *Store) DeleteReminder(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.index(name)
+	if i < 0 {
+		return fmt.Errorf("lembrete %q %w", name, ErrNotFound)
+	}
+	return s.update(func(data *StoreData) {
+		data.Reminders = slices.Delete(data.Reminders, i, i+1)
+	})
+}
+
+// AddDelivery registra um disparo antes da entrega e retorna o número dele.
+func (s *Store) AddDelivery(o Occurrence) (Delivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	d := Delivery{ID: s.nextID, Reminder: o.Reminder.Name, At: o.At, Snoozed: o.Snoozed}
+	err := s.update(func(data *StoreData) {
+		data.Deliveries = append(data.Deliveries, d)
+		if n := len(data.Deliveries); n > maxDeliveries {
+			data.Deliveries = data.Deliveries[n-maxDeliveries:]
+		}
+	})
+	if err != nil {
+		return Delivery{}, err
+	}
+	s.nextID++
+	return d, nil
+}
+
+// FinishDelivery anota o resultado da entrega. Uma entrega que falhou em
+// todos os canais continua pendente de confirmação.
+func (s *Store) FinishDelivery(id int64, deliverErr error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.delivery(id)
+	if i < 0 {
+		return fmt.Errorf("entrega %d %w", id, ErrNotFound)
+	}
+	now := s.Now()
+	return s.update(func(data *StoreData) {
+		d := &data.Deliveries[i]
+		d.DeliveredAt = &now
+		if deliverErr != nil {
+			d.Error = deliverErr.Error()
+		}
+	})
+}
+
+// Ack confirma a entrega. Confirmar de novo mantém o horário da primeira
+// confirmação.
+func (s *Store) Ack(id int64) (Delivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.delivery(id)
+	if i < 0 {
+		return Delivery{}, fmt.Errorf("entrega %d %w", id, ErrNotFound)
+	}
+	if s.data.Deliveries[i].AckedAt == nil {
+		now := s.Now()
+		err := s.update(func(data *StoreData) { data.Deliveries[i].AckedAt = &now })
+		if err != nil {
+			return Delivery{}, err
+		}
+	}
+	return s.data.Deliveries[i], nil
+}
+
+// AckLatest confirma a entrega pendente mais recente do lembrete.
+func (s *Store) AckLatest(name string) (Delivery, error) {
+	s.mu.Lock()
+	var id int64
+	for i := len(s.data.Deliveries) - 1; i >= 0; i-- {
+		if d := s.data.Deliveries[i]; d.Reminder == name && d.AckedAt == nil {
+			id = d.ID
+			break
+		}
+	}
+	s.mu.Unlock()
+
+	if id == 0 {
+		return Delivery{}, fmt.Errorf("nenhuma entrega pendente do lembrete %q: %w", name, ErrNotFound)
+	}
+	return s.Ack(id)
+}
+
+// Deliveries lista as entregas da mais recente para a mais antiga. Com
+// reminder não vazio, só as daquele lembrete; com pending, só as ainda não
+// confirmadas.
+func (s *Store) Deliveries(reminder string, pending bool) []Delivery {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list := []Delivery{}
+	for i := len(s.data.Deliveries) - 1; i >= 0; i-- {
+		d := s.data.Deliveries[i]
+		if (reminder == "" || d.Reminder == reminder) && (!pending || d.AckedAt == nil) {
+			list = append(list, d)
+		}
+	}
+	return list
+}
+
+func (s *Store) modify(name string, change func(*StoredReminder)) (StoredReminder, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.index(name)
+	if i < 0 {
+		return StoredReminder{}, fmt.Errorf("lembrete %q %w", name, ErrNotFound)
+	}
+	if err := s.update(func(data *StoreData) { change(&data.Reminders[i]) }); err != nil {
+		return StoredReminder{}, err
+	}
+	return s.data.Reminders[i], nil
+}
+
+func (s *Store) index(name string) int {
+	for i, sr := range s.data.Reminders {
+		if sr.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func (s *Store) delivery(id int64) int {
+	for i, d := range s.data.Deliveries {
+		if d.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// update aplica change a uma cópia dos dados e só passa a usá-la depois de
+// gravada, para que uma falha ao gravar não deixe a memória diferente do
+// arquivo. Deve ser chamado com mu travado.
+func (s *Store) update(change func(*StoreData)) error {
+	data := StoreData{
+		Reminders:  slices.Clone(s.data.Reminders),
+		Deliveries: slices.Clone(s.data.Deliveries),
+	}
+	change(&data)
+	if err := s.save(data); err != nil {
+		return err
+	}
+	s.data = data
+	return nil
+}
+
+// save grava data em um temporário ao lado do arquivo e o renomeia por cima,
+// para que uma queda no meio da gravação não deixe o Store pela metade. O
+// arquivo fica com a permissão 0600 do os.CreateTemp: só o dono o lê.
+func (s *Store) save(data StoreData) error {
+	if s.Path == "" {
+		return nil
+	}
+	b, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.Path), filepath.Base(s.Path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(append(b, '\n'))
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.Path)
+}
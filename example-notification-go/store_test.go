@@ -0,0 +1,136 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func reminderConfig(name string) ReminderConfig {
+	return ReminderConfig{Name: name, Every: Duration(30 * time.Minute), Message: "lembrete " + name}
+}
+
+func mustStore(t *testing.T, path string) *Store {
+	t.Helper()
+	s, _, err := OpenStore(path, func() time.Time { return day(7, 9, 0) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestStoreAddRemindersDuplicate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lembretes.json")
+	s := mustStore(t, path)
+	if err := s.AddReminders(reminderConfig("agua")); err != nil {
+		t.Fatal(err)
+	}
+
+	// Nome repetido dentro do mesmo lote ou já guardado: nada é guardado
+	for _, batch := range [][]ReminderConfig{
+		{reminderConfig("pausa"), reminderConfig("pausa")},
+		{reminderConfig("pausa"), reminderConfig("agua")},
+	} {
+		if err := s.AddReminders(batch...); !errors.Is(err, ErrExists) {
+			t.Errorf("AddReminders(%s, %s) = %v, quer ErrExists", batch[0].Name, batch[1].Name, err)
+		}
+	}
+	if got := s.Reminders(); len(got) != 1 || got[0].Name != "agua" {
+		t.Errorf("lembretes = %+v, quer só agua", got)
+	}
+	if got := mustStore(t, path).Reminders(); len(got) != 1 {
+		t.Errorf("lembretes no arquivo = %+v, quer só agua", got)
+	}
+}
+
+func TestStoreRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lembretes.json")
+	s := mustStore(t, path)
+	if err := s.AddReminders(reminderConfig("agua"), reminderConfig("pausa")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.SetPaused("pausa", true); err != nil {
+		t.Fatal(err)
+	}
+	d, err := s.AddDelivery(Occurrence{Reminder: Reminder{Name: "agua"}, At: day(7, 9, 0)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.FinishDelivery(d.ID, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened := mustStore(t, path)
+	if got, want := reopened.Reminders(), s.Reminders(); !reflect.DeepEqual(got, want) {
+		t.Errorf("lembretes relidos = %+v, quer %+v", got, want)
+	}
+	if got, want := reopened.Deliveries("", false), s.Deliveries("", false); !reflect.DeepEqual(got, want) {
+		t.Errorf("entregas relidas = %+v, quer %+v", got, want)
+	}
+	// A numeração continua depois da última entrega
+	next, err := reopened.AddDelivery(Occurrence{Reminder: Reminder{Name: "agua"}, At: day(7, 9, 30)})
+	if err != nil || next.ID != d.ID+1 {
+		t.Errorf("AddDelivery = %+v, %v; quer ID %d", next, err, d.ID+1)
+	}
+}
+
+func TestStoreSaveError(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "dados")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	s := mustStore(t, filepath.Join(dir, "lembretes.json"))
+	if err := s.AddReminders(reminderConfig("agua")); err != nil {
+		t.Fatal(err)
+	}
+	d, err := s.AddDelivery(Occurrence{Reminder: Reminder{Name: "agua"}, At: day(7, 9, 0)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	reminders, deliveries := s.Reminders(), s.Deliveries("", false)
+
+	// Sem o diretório, toda gravação falha e a memória fica como estava
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AddReminders(reminderConfig("pausa")); err == nil {
+		t.Error("AddReminders gravou sem o diretório")
+	}
+	if _, err := s.UpdateReminder(reminderConfig("agua")); err == nil {
+		t.Error("UpdateReminder gravou sem o diretório")
+	}
+	if _, err := s.SetPaused("agua", true); err == nil {
+		t.Error("SetPaused gravou sem o diretório")
+	}
+	if _, err := s.AddDelivery(Occurrence{Reminder: Reminder{Name: "agua"}, At: day(7, 9, 30)}); err == nil {
+		t.Error("AddDelivery gravou sem o diretório")
+	}
+	if err := s.FinishDelivery(d.ID, errors.New("falhou")); err == nil {
+		t.Error("FinishDelivery gravou sem o diretório")
+	}
+	if _, err := s.Ack(d.ID); err == nil {
+		t.Error("Ack gravou sem o diretório")
+	}
+	if err := s.DeleteReminder("agua"); err == nil {
+		t.Error("DeleteReminder gravou sem o diretório")
+	}
+
+	if got := s.Reminders(); !reflect.DeepEqual(got, reminders) {
+		t.Errorf("lembretes = %+v, quer %+v", got, reminders)
+	}
+	if got := s.Deliveries("", false); !reflect.DeepEqual(got, deliveries) {
+		t.Errorf("entregas = %+v, quer %+v", got, deliveries)
+	}
+
+	// Com o diretório de volta, o número da entrega perdida é reaproveitado
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	next, err := s.AddDelivery(Occurrence{Reminder: Reminder{Name: "agua"}, At: day(7, 9, 30)})
+	if err != nil || next.ID != d.ID+1 {
+		t.Errorf("AddDelivery = %+v, %v; quer ID %d", next, err, d.ID+1)
+	}
+}
@@ -0,0 +1,328 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/andybalholm/cascadia"
+	"github.com/antchfx/htmlquery"
+	"golang.org/x/net/html"
+)
+
+const (
+	defaultUserAgent = "example-web-scraping-golang/1.0"
+	defaultFollow    = "a[href]"
+	maxPageSize      = 10 << 20
+)
+
+// Crawler baixa páginas por HTTP, sem navegador, seguindo links dentro dos
+// domínios do cenário e aplicando os mesmos passos extract do modo com
+// navegador. Respeita o robots.txt e um intervalo mínimo por host, que vale
+// para todos os cenários rodando no mesmo Crawler.
+type Crawler struct {
+	Client    *http.Client
+	UserAgent string
+	Delay     time.Duration // usado quando o cenário não define crawl.delay
+
+	mu    sync.Mutex
+	hosts map[string]*hostState
+}
+
+type hostState struct {
+	once   sync.Once
+	robots *Robots
+	next   time.Time // próximo horário livre para uma requisição
+}
+
+// NewCrawler cria um Crawler com os valores padrão: um segundo entre
+// requisições ao mesmo host.
+func NewCrawler() *Crawler {
+	return &Crawler{
+		Client:    &http.Client{Timeout: 30 * time.Second},
+		UserAgent: defaultUserAgent,
+		Delay:     time.Second,
+		hosts:     make(map[string]*hostState),
+	}
+}
+
+type crawlItem struct {
+	url   *url.URL
+	depth int
+}
+
+// Crawl percorre o site a partir das URLs dos passos navigate, em largura,
+// e retorna um registro por página baixada, com o campo "url". Páginas que
+// falham são registradas no log e puladas; só um seletor inválido ou o
+// cancelamento de ctx interrompem a varredura.
+func (c *Crawler) Crawl(ctx context.Context, sc Scenario) ([]Record, error) {
+	if err := sc.ValidateStatic(); err != nil {
+		return nil, err
+	}
+	cfg := sc.Crawl
+	follow, err := cascadia.Compile(cmp.Or(cfg.Follow, defaultFollow))
+	if err != nil {
+		return nil, fmt.Errorf("crawl.follow: %w", err)
+	}
+	delay := cmp.Or(cfg.Delay, c.Delay)
+	rules, nexts := sc.staticSteps()
+
+	domains := cfg.Domains
+	if len(domains) == 0 {
+		for _, start := range sc.StartURLs() {
+			if u, err := url.Parse(start); err == nil {
+				domains = append(domains, u.Hostname())
+			}
+		}
+	}
+
+	var queue []crawlItem
+	seen := make(map[string]bool)
+	add := func(base *url.URL, ref string, depth int) {
+		u, err := url.Parse(strings.TrimSpace(ref))
+		if err != nil {
+			return
+		}
+		if base != nil {
+			u = base.ResolveReference(u)
+		}
+		if (u.Scheme != "http" && u.Scheme != "https") || !inDomains(u.Hostname(), domains) {
+			return
+		}
+		normalizeURL(u)
+		if key := u.String(); !seen[key] {
+			seen[key] = true
+			queue = append(queue, crawlItem{url: u, depth: depth})
+		}
+	}
+	for _, start := range sc.StartURLs() {
+		add(nil, start, 0)
+	}
+
+	var records []Record
+	fetched := 0
+	for i := 0; i < len(queue); i++ {
+		if cfg.MaxPages > 0 && fetched >= cfg.MaxPages {
+			break
+		}
+		if err := ctx.Err(); err != nil {
+			return records, err
+		}
+		item := queue[i]
+
+		robots := c.robots(ctx, item.url)
+		if !robots.Allowed(item.url.RequestURI()) {
+			log.Printf("%s: bloqueado pelo robots.txt", item.url)
+			continue
+		}
+		if err := c.wait(ctx, item.url, max(delay, robots.CrawlDelay)); err != nil {
+			return records, err
+		}
+
+		fetched++
+		doc, final, err := c.fetch(ctx, item.url)
+		if err != nil {
+			log.Printf("%s: %v", item.url, err)
+			continue
+		}
+		if !inDomains(final.Hostname(), domains) {
+			log.Printf("%s: redirecionado para fora dos domínios permitidos (%s)", item.url, final)
+			continue
+		}
+		normalizeURL(final)
+		seen[final.String()] = true
+
+		record := Record{"url": final.String()}
+		for _, rule := range rules {
+			value, ok, err := extractStatic(doc, rule)
+			if err != nil {
+				return records, fmt.Errorf("campo %s: %w", rule.Field, err)
+			}
+			if ok {
+				record[rule.Field] = value
+			}
+		}
+		records = append(records, record)
+
+		if item.depth < cfg.Depth {
+			for _, a := range cascadia.QueryAll(doc, follow) {
+				add(final, htmlquery.SelectAttr(a, "href"), item.depth+1)
+			}
+		}
+		// O link de próxima página não conta como um nível a mais, assim
+		// como o clique em Next no modo com navegador.
+		for _, next := range nexts {
+			nodes, err := next.findAll(doc)
+			if err != nil {
+				return records, fmt.Errorf("next: %w", err)
+			}
+			for _, n := range nodes {
+				if href := linkHref(n); href != "" {
+					add(final, href, item.depth)
+				}
+			}
+		}
+	}
+	return records, nil
+}
+
+// staticSteps separa os passos usados no modo estático: os extract, inclusive
+// os de dentro de paginate, e os seletores Next dos paginate.
+func (sc Scenario) staticSteps() (rules, nexts []Step) {
+	for _, s := range sc.Steps {
+		switch s.Action {
+		case ActionExtract:
+			rules = append(rules, s)
+		case ActionPaginate:
+			nexts = append(nexts, Step{Selector: s.Next, By: s.By})
+			for _, sub := range s.Steps {
+				if sub.Action == ActionExtract {
+					rules = append(rules, sub)
+				}
+			}
+		}
+	}
+	return rules, nexts
+}
+
+// fetch baixa a página e a interpreta como HTML. Retorna também a URL final,
+// depois de redirecionamentos, usada para resolver os links relativos.
+func (c *Crawler) fetch(ctx context.Context, u *url.URL) (*html.Node, *url.URL, error) {
+	resp, err := c.get(ctx, u.String())
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, nil, fmt.Errorf("status %s", resp.Status)
+	}
+	if mt, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mt != "text/html" && mt != "application/xhtml+xml" {
+		return nil, nil, fmt.Errorf("conteúdo %q não é HTML", mt)
+	}
+	doc, err := html.Parse(io.LimitReader(resp.Body, maxPageSize))
+	if err != nil {
+		return nil, nil, err
+	}
+	return doc, resp.Request.URL, nil
+}
+
+func (c *Crawler) get(ctx context.Context, rawURL string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", c.UserAgent)
+	return c.Client.Do(req)
+}
+
+// host retorna o estado do host de u, criando-o na primeira vez.
+func (c *Crawler) host(u *url.URL) *hostState {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := u.Scheme + "://" + u.Host
+	h, ok := c.hosts[key]
+	if !ok {
+		h = &hostState{}
+		c.hosts[key] = h
+	}
+	return h
+}
+
+// robots retorna as regras do robots.txt do host de u, baixado uma única vez.
+// Sem robots.txt (4xx) tudo é permitido; se o servidor responder com erro,
+// nada é.
+func (c *Crawler) robots(ctx context.Context, u *url.URL) *Robots {
+	h := c.host(u)
+	h.once.Do(func() {
+		robotsURL := &url.URL{Scheme: u.Scheme, Host: u.Host, Path: "/robots.txt"}
+		resp, err := c.get(ctx, robotsURL.String())
+		if err != nil {
+			// Host inacessível: o erro aparece ao baixar a própria página.
+			h.robots = AllowAll
+			return
+		}
+		defer resp.Body.Close()
+
+		switch {
+		case resp.StatusCode >= 200 && resp.StatusCode <= 299:
+			h.robots = ParseRobots(io.LimitReader(resp.Body, 512<<10), c.UserAgent)
+		case resp.StatusCode >= 400 && resp.StatusCode <= 499:
+			h.robots = AllowAll
+		default:
+			log.Printf("%s: status %s; nenhuma página do host será baixada", robotsURL, resp.Status)
+			h.robots = DisallowAll
+		}
+	})
+	return h.robots
+}
+
+// wait reserva o próximo horário livre do host de u e espera até ele.
+func (c *Crawler) wait(ctx context.Context, u *url.URL, delay time.Duration) error {
+	h := c.host(u)
+	c.mu.Lock()
+	at := time.Now()
+	if h.next.After(at) {
+		at = h.next
+	}
+	h.next = at.Add(delay)
+	c.mu.Unlock()
+
+	timer := time.NewTimer(time.Until(at))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// inDomains informa se host é um dos domínios ou subdomínio de algum deles.
+func inDomains(host string, domains []string) bool {
+	host = strings.ToLower(host)
+	for _, d := range domains {
+		d = strings.ToLower(strings.TrimPrefix(d, "."))
+		if host == d || strings.HasSuffix(host, "."+d) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeURL remove o fragmento, a porta padrão e diferenças de caixa no
+// host, para que a mesma página não seja baixada duas vezes.
+func normalizeURL(u *url.URL) {
+	u.Fragment = ""
+	u.RawFragment = ""
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	if port := u.Port(); (u.Scheme == "http" && port == "80") || (u.Scheme == "https" && port == "443") {
+		u.Host = strings.TrimSuffix(u.Host, ":"+port)
+	}
+	if u.Path == "" {
+		u.Path = "/"
+	}
+}
+
+var linkSelector = cascadia.MustCompile("a[href]")
+
+// linkHref retorna o href do elemento ou, se ele não for um link (como um
+// <li class="next">), do primeiro link dentro dele.
+func linkHref(n *html.Node) string {
+	if href := htmlquery.SelectAttr(n, "href"); href != "" {
+		return href
+	}
+	if a := cascadia.Query(n, linkSelector); a != nil {
+		return htmlquery.SelectAttr(a, "href")
+	}
+	return ""
+}
@@ -0,0 +1,277 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// site é um servidor de teste que registra cada requisição.
+type site struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	requests []string
+	times    []time.Time
+}
+
+// newSite serve robots e as páginas do mapa, em text/html; outros caminhos
+// respondem 404.
+func newSite(t *testing.T, robots string, robotsStatus int, pages map[string]string) *site {
+	t.Helper()
+	s := &site{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests = append(s.requests, r.URL.RequestURI())
+		s.times = append(s.times, time.Now())
+		s.mu.Unlock()
+
+		if r.URL.Path == "/robots.txt" {
+			w.WriteHeader(robotsStatus)
+			fmt.Fprint(w, robots)
+			return
+		}
+		body, ok := pages[r.URL.RequestURI()]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprintf(w, "<!doctype html><html><body>%s</body></html>", body)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *site) paths() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.requests)
+}
+
+func crawlScenario(start string, depth int) Scenario {
+	return Scenario{
+		Name:  "teste",
+		Crawl: Crawl{Depth: depth, Delay: time.Millisecond},
+		Steps: []Step{
+			{Action: ActionNavigate, URL: start},
+			{Action: ActionExtract, Selector: "h1", Field: "titulo"},
+		},
+	}
+}
+
+func recordURLs(records []Record) []string {
+	var urls []string
+	for _, r := range records {
+		urls = append(urls, r["url"].(string))
+	}
+	return urls
+}
+
+func TestCrawlDedupe(t *testing.T) {
+	s := newSite(t, "", http.StatusNotFound, map[string]string{
+		"/": `<h1>Início</h1>
+			<a href="/a">a</a> <a href="/a#topo">a de novo</a> <a href="a">relativo</a>
+			<a href="/b?p=1">b</a> <a href="/b?p=1">b repetido</a>
+			<a href="https://outro.exemplo/x">fora do domínio</a>
+			<a href="mailto:x@exemplo.com">e-mail</a>`,
+		"/a":     `<h1>A</h1><a href="/">início</a><a href="/b?p=1#fim">b</a>`,
+		"/b?p=1": `<h1>B</h1><a href="/a">a</a>`,
+	})
+
+	c := NewCrawler()
+	records, err := c.Crawl(context.Background(), crawlScenario(s.URL+"/#x", 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{s.URL + "/", s.URL + "/a", s.URL + "/b?p=1"}
+	if got := recordURLs(records); !slices.Equal(got, want) {
+		t.Errorf("páginas = %v, quer %v", got, want)
+	}
+	if got, want := s.paths(), []string{"/robots.txt", "/", "/a", "/b?p=1"}; !slices.Equal(got, want) {
+		t.Errorf("requisições = %v, quer %v (cada página uma vez)", got, want)
+	}
+	if records[1]["titulo"] != "A" {
+		t.Errorf("registro = %v", records[1])
+	}
+}
+
+func TestCrawlDepth(t *testing.T) {
+	s := newSite(t, "", http.StatusNotFound, map[string]string{
+		"/":  `<h1>0</h1><a href="/1">1</a>`,
+		"/1": `<h1>1</h1><a href="/2">2</a>`,
+		"/2": `<h1>2</h1>`,
+	})
+	records, err := NewCrawler().Crawl(context.Background(), crawlScenario(s.URL, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := recordURLs(records), []string{s.URL + "/", s.URL + "/1"}; !slices.Equal(got, want) {
+		t.Errorf("páginas = %v, quer %v", got, want)
+	}
+}
+
+func TestCrawlRobots(t *testing.T) {
+	robots := `User-agent: *
+Disallow: /
+
+User-agent: example-web-scraping-golang
+Disallow: /privado
+Allow: /privado/publico
+Disallow: /*.pdf$
+`
+	s := newSite(t, robots, http.StatusOK, map[string]string{
+		"/": `<h1>Início</h1>
+			<a href="/privado/x">privado</a>
+			<a href="/privado/publico">público</a>
+			<a href="/doc.pdf">pdf</a>
+			<a href="/doc.pdf?v=2">pdf com query</a>`,
+		"/privado/publico": `<h1>Público</h1>`,
+		"/doc.pdf?v=2":     `<h1>PDF</h1>`,
+	})
+
+	records, err := NewCrawler().Crawl(context.Background(), crawlScenario(s.URL, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{s.URL + "/", s.URL + "/privado/publico", s.URL + "/doc.pdf?v=2"}
+	if got := recordURLs(records); !slices.Equal(got, want) {
+		t.Errorf("páginas = %v, quer %v", got, want)
+	}
+	for _, p := range s.paths() {
+		if p == "/privado/x" || p == "/doc.pdf" {
+			t.Errorf("baixou %s, bloqueado pelo robots.txt", p)
+		}
+	}
+}
+
+func TestCrawlRobotsServerError(t *testing.T) {
+	s := newSite(t, "", http.StatusServiceUnavailable, map[string]string{"/": `<h1>Início</h1>`})
+	records, err := NewCrawler().Crawl(context.Background(), crawlScenario(s.URL, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 0 || !slices.Equal(s.paths(), []string{"/robots.txt"}) {
+		t.Errorf("registros = %v, requisições = %v; com robots.txt em erro nada deve ser baixado", records, s.paths())
+	}
+}
+
+func TestCrawlDelay(t *testing.T) {
+	const delay = 150 * time.Millisecond
+	s := newSite(t, "User-agent: *\nCrawl-delay: 0.05\n", http.StatusOK, map[string]string{
+		"/":  `<h1>0</h1><a href="/1">1</a><a href="/2">2</a>`,
+		"/1": `<h1>1</h1>`,
+		"/2": `<h1>2</h1>`,
+	})
+	sc := crawlScenario(s.URL, 1)
+	sc.Crawl.Delay = delay
+
+	if _, err := NewCrawler().Crawl(context.Background(), sc); err != nil {
+		t.Fatal(err)
+	}
+
+	// O intervalo vale entre as páginas; o robots.txt não conta
+	s.mu.Lock()
+	times := s.times[1:]
+	s.mu.Unlock()
+	if len(times) != 3 {
+		t.Fatalf("requisições = %v", s.paths())
+	}
+	for i := 1; i < len(times); i++ {
+		// Uma pequena folga para a precisão do relógio
+		if gap := times[i].Sub(times[i-1]); gap < delay-10*time.Millisecond {
+			t.Errorf("intervalo entre as páginas %d e %d = %v, quer ao menos %v", i-1, i, gap, delay)
+		}
+	}
+}
+
+func TestCrawlDelaySharedByScenarios(t *testing.T) {
+	// Dois cenários no mesmo Crawler dividem o intervalo do host
+	const delay = 100 * time.Millisecond
+	s := newSite(t, "", http.StatusNotFound, map[string]string{
+		"/a": `<h1>a</h1>`,
+		"/b": `<h1>b</h1>`,
+	})
+	c := NewCrawler()
+	var wg sync.WaitGroup
+	for _, path := range []string{"/a", "/b"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sc := crawlScenario(s.URL+path, 0)
+			sc.Crawl.Delay = delay
+			if _, err := c.Crawl(context.Background(), sc); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var pages []time.Time
+	for i, p := range s.requests {
+		if !strings.HasSuffix(p, "robots.txt") {
+			pages = append(pages, s.times[i])
+		}
+	}
+	if len(pages) != 2 {
+		t.Fatalf("requisições = %v", s.requests)
+	}
+	if gap := pages[1].Sub(pages[0]); gap < delay-10*time.Millisecond {
+		t.Errorf("intervalo entre cenários = %v, quer ao menos %v", gap, delay)
+	}
+}
+
+func TestParseRobots(t *testing.T) {
+	robots := `# comentário
+User-agent: Googlebot
+Disallow: /
+
+User-agent: outro
+User-agent: EXAMPLE-web-scraping
+Disallow: /admin
+Allow: /admin/ajuda
+Disallow: /*?sessao=
+Crawl-delay: 2.5
+
+User-agent: *
+Disallow:
+`
+	r := ParseRobots(strings.NewReader(robots), defaultUserAgent)
+	tests := []struct {
+		path    string
+		allowed bool
+	}{
+		{"/", true},
+		{"/admin", false},
+		{"/admin/usuarios", false},
+		{"/admin/ajuda", true},
+		{"/admin/ajuda/x", true},
+		{"/busca?sessao=abc&q=1", false},
+		{"/busca?q=1", true},
+	}
+	for _, tt := range tests {
+		if got := r.Allowed(tt.path); got != tt.allowed {
+			t.Errorf("Allowed(%q) = %v, quer %v", tt.path, got, tt.allowed)
+		}
+	}
+	if r.CrawlDelay != 2500*time.Millisecond {
+		t.Errorf("CrawlDelay = %v, quer 2.5s", r.CrawlDelay)
+	}
+
+	// Sem grupo próprio vale o "*"; "Disallow:" vazio libera tudo
+	if r := ParseRobots(strings.NewReader(robots), "qualquer"); !r.Allowed("/admin") {
+		t.Error(`grupo "*" deveria liberar /admin`)
+	}
+	if r := ParseRobots(strings.NewReader(robots), "googlebot/2.1"); r.Allowed("/x") {
+		t.Error("grupo Googlebot deveria bloquear tudo")
+	}
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/dom"
+	"github.com/chromedp/cdproto/runtime"
+	"github.com/chromedp/chromedp"
+)
+
+// nodeValueJS retorna o texto visível do elemento ou, se attr for informado,
+// o valor do atributo.
+const nodeValueJS = `function(attr) {
+	return (attr ? this.getAttribute(attr) : this.innerText) || "";
+}`
+
+// tableJS retorna as células de uma <table> como uma matriz de textos.
+const tableJS = `function() {
+	return Array.from(this.rows, r => Array.from(r.cells, c => c.innerText.trim()));
+}`
+
+// query retorna a opção de busca do primeiro elemento que casa com Selector.
+func (s Step) query() chromedp.QueryOption {
+	if s.By == ByXPath {
+		return chromedp.BySearch
+	}
+	return chromedp.ByQuery
+}
+
+// queryAll retorna a opção de busca de todos os elementos que casam com Selector.
+func (s Step) queryAll() chromedp.QueryOption {
+	if s.By == ByXPath {
+		return chromedp.BySearch
+	}
+	return chromedp.ByQueryAll
+}
+
+// extract lê o conteúdo de Selector conforme Kind. O resultado é uma string
+// para text e attr, []string para list e []map[string]string para table.
+// Só list não espera o seletor: sem elementos, o resultado é nil.
+func extract(ctx context.Context, s Step) (any, error) {
+	switch s.Kind {
+	case "", KindText:
+		var text string
+		err := chromedp.Run(ctx, chromedp.Text(s.Selector, &text, s.query()))
+		return strings.TrimSpace(text), err
+
+	case KindAttr:
+		var value string
+		var ok bool
+		err := chromedp.Run(ctx, chromedp.AttributeValue(s.Selector, s.Attr, &value, &ok, s.query()))
+		return value, err
+
+	case KindList:
+		var values []string
+		err := chromedp.Run(ctx, chromedp.QueryAfter(s.Selector, func(ctx context.Context, _ runtime.ExecutionContextID, nodes ...*cdp.Node) error {
+			for _, n := range nodes {
+				var v string
+				if err := callOnNode(ctx, n, nodeValueJS, &v, s.Attr); err != nil {
+					return err
+				}
+				values = append(values, strings.TrimSpace(v))
+			}
+			return nil
+		}, s.queryAll(), chromedp.AtLeast(0)))
+		if len(values) == 0 {
+			return nil, err
+		}
+		return values, err
+
+	case KindTable:
+		var cells [][]string
+		err := chromedp.Run(ctx, chromedp.QueryAfter(s.Selector, func(ctx context.Context, _ runtime.ExecutionContextID, nodes ...*cdp.Node) error {
+			return callOnNode(ctx, nodes[0], tableJS, &cells)
+		}, s.query()))
+		return tableRows(cells), err
+
+	default:
+		return nil, fmt.Errorf("kind desconhecido %q", s.Kind)
+	}
+}
+
+// tableRows usa a primeira linha como cabeçalho e transforma as demais em
+// mapas coluna → valor. Colunas sem nome viram col1, col2...
+func tableRows(cells [][]string) []map[string]string {
+	if len(cells) == 0 {
+		return nil
+	}
+	header := cells[0]
+	rows := make([]map[string]string, 0, len(cells)-1)
+	for _, line := range cells[1:] {
+		row := make(map[string]string, len(line))
+		for j, v := range line {
+			name := fmt.Sprintf("col%d", j+1)
+			if j < len(header) && header[j] != "" {
+				name = header[j]
+			}
+			row[name] = v
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// callOnNode executa a função JavaScript com this apontando para o nó e
+// guarda o retorno em res.
+func callOnNode(ctx context.Context, node *cdp.Node, function string, res any, args ...any) error {
+	obj, err := dom.ResolveNode().WithNodeID(node.NodeID).Do(ctx)
+	if err != nil {
+		return err
+	}
+	defer runtime.ReleaseObject(obj.ObjectID).Do(ctx)
+
+	return chromedp.CallFunctionOn(function, res,
+		func(p *runtime.CallFunctionOnParams) *runtime.CallFunctionOnParams {
+			return p.WithObjectID(obj.ObjectID)
+		},
+		args...,
+	).Do(ctx)
+}
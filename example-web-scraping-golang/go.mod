@@ -2,15 +2,24 @@ module example-web-scraping-golang
 
 go 1.22.6
 
-require github.com/chromedp/chromedp v0.10.0
+require (
+	github.com/andybalholm/cascadia v1.3.2
+	github.com/antchfx/htmlquery v1.3.3
+	github.com/chromedp/cdproto v0.0.0-20240801214329-3f85d328b335
+	github.com/chromedp/chromedp v0.10.0
+	golang.org/x/net v0.30.0
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require (
-	github.com/chromedp/cdproto v0.0.0-20240801214329-3f85d328b335 // indirect
+	github.com/antchfx/xpath v1.3.2 // indirect
 	github.com/chromedp/sysutil v1.0.0 // indirect
 	github.com/gobwas/httphead v0.1.0 // indirect
 	github.com/gobwas/pool v0.2.1 // indirect
 	github.com/gobwas/ws v1.4.0 // indirect
+	github.com/golang/groupcache v0.0.0-20210331224755-41bb18bfe9da // indirect
 	github.com/josharian/intern v1.0.0 // indirect
 	github.com/mailru/easyjson v0.7.7 // indirect
-	golang.org/x/sys v0.22.0 // indirect
+	golang.org/x/sys v0.26.0 // indirect
+	golang.org/x/text v0.19.0 // indirect
 )
@@ -2,42 +2,161 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"log"
+	"os"
+	"os/signal"
+	"sync"
 	"time"
 
 	"github.com/chromedp/chromedp"
 )
 
 func main() {
-	options := append(chromedp.DefaultExecAllocatorOptions[:],
-		chromedp.Flag("headless", false),
-		chromedp.Flag("disable-gpu", true),
-	)
+	os.Exit(scrape())
+}
 
-	term := "golang documentation"
+// scrape executa os cenários e retorna o código de saída: 1 se algum falhou.
+// Fica fora de main para que os defers fechem o navegador antes do os.Exit.
+func scrape() int {
+	scenarioPath := flag.String("scenario", "cenarios/estou-com-sorte.yaml", "arquivo YAML ou JSON com o cenário de raspagem (outros podem vir como argumentos)")
+	format := flag.String("format", "jsonl", "formato da saída: jsonl ou csv")
+	output := flag.String("o", "", "arquivo de saída (padrão: saída padrão)")
+	headless := flag.Bool("headless", true, "executa o navegador sem janela")
+	chrome := flag.String("chrome", "", "caminho do executável do Chrome (padrão: procura no sistema)")
+	noSandbox := flag.Bool("no-sandbox", false, "desativa o sandbox do Chrome (necessário como root em contêineres)")
+	tabs := flag.Int("tabs", 4, "número máximo de abas abertas ao mesmo tempo")
+	timeout := flag.Duration("timeout", 2*time.Minute, "tempo máximo de cada cenário")
+	retries := flag.Int("retries", 2, "novas tentativas quando a navegação falha")
+	static := flag.Bool("static", false, "baixa as páginas por HTTP, sem navegador, seguindo a seção crawl do cenário")
+	userAgent := flag.String("user-agent", defaultUserAgent, "user-agent do modo estático, também usado no robots.txt")
+	flag.Parse()
+	if *format != "jsonl" && *format != "csv" {
+		log.Fatalf("formato desconhecido %q (use jsonl ou csv)", *format)
+	}
 
-	allocCtx, _ := chromedp.NewExecAllocator(context.Background(), options...)
-	ctx, cancel := chromedp.NewContext(allocCtx)
-	defer cancel()
+	paths := flag.Args()
+	if len(paths) == 0 {
+		paths = []string{*scenarioPath}
+	}
+	scenarios := make([]Scenario, len(paths))
+	for i, path := range paths {
+		sc, err := LoadScenario(path)
+		if err == nil && *static {
+			err = sc.ValidateStatic()
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		scenarios[i] = sc
+	}
 
-	err := chromedp.Run(ctx,
-		chromedp.Tasks{
-			chromedp.Navigate("https://www.google.com"),
-			chromedp.WaitReady("body", chromedp.ByQuery),
+	// Ctrl+C interrompe os cenários em andamento, mas o que já foi extraído
+	// ainda é gravado.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
 
-			chromedp.WaitVisible(`textarea[name="q"]`, chromedp.ByQuery),
-			chromedp.SendKeys(`textarea[name="q"]`, term, chromedp.ByQuery),
+	var run func(context.Context, Scenario) ([]Record, error)
+	fields := Scenario.Fields
+	if *static {
+		crawler := NewCrawler()
+		crawler.UserAgent = *userAgent
+		run = crawler.Crawl
+		fields = func(sc Scenario) []string { return append([]string{"url"}, sc.Fields()...) }
+	} else {
+		pool, err := startPool(*headless, *chrome, *noSandbox, *tabs)
+		if err != nil {
+			log.Fatalf("não foi possível iniciar o navegador: %v", err)
+		}
+		defer pool.Close()
+		pool.Timeout = *timeout
+		pool.Runner.Retries = *retries
+		pool.Runner.RetryDelay = time.Second
+		run = pool.Run
+	}
 
-			chromedp.Click(`input[name="btnI"]`, chromedp.ByQuery),
+	results := make([][]Record, len(scenarios))
+	errs := make([]error, len(scenarios))
+	var wg sync.WaitGroup
+	for i, sc := range scenarios {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], errs[i] = run(ctx, sc)
+		}()
+	}
+	wg.Wait()
 
-			chromedp.Sleep(25 * time.Second),
-		},
-	)
+	records, columns := collect(scenarios, results, fields)
 
-	if err != nil {
-		log.Fatal(err)
+	out := os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			log.Print(err)
+			return 1
+		}
+		defer f.Close()
+		out = f
+	}
+	if err := WriteRecords(out, *format, columns, records); err != nil {
+		log.Print(err)
+		return 1
 	}
 
-	fmt.Println("Página 'Estou com sorte' aberta com sucesso!")
+	failed := 0
+	for i, sc := range scenarios {
+		if errs[i] != nil {
+			failed++
+			log.Printf("Cenário '%s' falhou: %v", sc.Name, errs[i])
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "Cenário '%s' executado com sucesso! %d registro(s) extraído(s).\n", sc.Name, len(results[i]))
+	}
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+// collect junta os registros de todos os cenários na ordem dos arquivos.
+// Com mais de um cenário, cada registro ganha o campo "cenario" e as colunas
+// são a união dos campos de todos eles.
+func collect(scenarios []Scenario, results [][]Record, fieldsOf func(Scenario) []string) ([]Record, []string) {
+	if len(scenarios) == 1 {
+		return results[0], fieldsOf(scenarios[0])
+	}
+
+	fields := []string{"cenario"}
+	seen := map[string]bool{"cenario": true}
+	var records []Record
+	for i, sc := range scenarios {
+		for _, f := range fieldsOf(sc) {
+			if !seen[f] {
+				seen[f] = true
+				fields = append(fields, f)
+			}
+		}
+		for _, r := range results[i] {
+			r["cenario"] = sc.Name
+			records = append(records, r)
+		}
+	}
+	return records, fields
+}
+
+// startPool inicia o navegador do modo padrão.
+func startPool(headless bool, chrome string, noSandbox bool, tabs int) (*Pool, error) {
+	options := append(chromedp.DefaultExecAllocatorOptions[:],
+		chromedp.Flag("headless", headless),
+		chromedp.Flag("disable-gpu", true),
+	)
+	if chrome != "" {
+		options = append(options, chromedp.ExecPath(chrome))
+	}
+	if noSandbox {
+		options = append(options, chromedp.NoSandbox)
+	}
+	return NewPool(tabs, options...)
 }
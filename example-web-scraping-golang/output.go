@@ -0,0 +1,58 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// WriteRecords grava os registros no formato indicado: jsonl (um objeto JSON
+// por linha) ou csv (uma coluna por campo, na ordem de fields).
+func WriteRecords(w io.Writer, format string, fields []string, records []Record) error {
+	switch format {
+	case "jsonl":
+		return WriteJSONL(w, records)
+	case "csv":
+		return WriteCSV(w, fields, records)
+	default:
+		return fmt.Errorf("formato desconhecido %q (use jsonl ou csv)", format)
+	}
+}
+
+// WriteJSONL grava um registro por linha.
+func WriteJSONL(w io.Writer, records []Record) error {
+	enc := json.NewEncoder(w)
+	for _, r := range records {
+		if err := enc.Encode(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteCSV grava um cabeçalho com os campos e uma linha por registro. Listas
+// e tabelas não cabem em uma célula e são gravadas como JSON.
+func WriteCSV(w io.Writer, fields []string, records []Record) error {
+	cw := csv.NewWriter(w)
+	cw.Write(fields)
+	for _, r := range records {
+		line := make([]string, len(fields))
+		for i, f := range fields {
+			switch v := r[f].(type) {
+			case nil:
+			case string:
+				line[i] = v
+			default:
+				data, err := json.Marshal(v)
+				if err != nil {
+					return err
+				}
+				line[i] = string(data)
+			}
+		}
+		cw.Write(line)
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/chromedp/chromedp"
+)
+
+// Pool mantém um único navegador aberto e executa cenários em abas
+// separadas, no máximo tabs ao mesmo tempo.
+type Pool struct {
+	Runner  *Runner
+	Timeout time.Duration // tempo máximo de cada cenário; zero desativa
+
+	browser     context.Context
+	cancelAlloc context.CancelFunc
+	cancel      context.CancelFunc
+	tabs        chan struct{}
+}
+
+// NewPool inicia o navegador com as opções do allocator e espera ele
+// responder, para que um Chrome ausente ou quebrado apareça logo.
+func NewPool(tabs int, opts ...chromedp.ExecAllocatorOption) (*Pool, error) {
+	allocCtx, cancelAlloc := chromedp.NewExecAllocator(context.Background(), opts...)
+	browser, cancel := chromedp.NewContext(allocCtx)
+	if err := chromedp.Run(browser); err != nil {
+		cancel()
+		cancelAlloc()
+		return nil, err
+	}
+	return &Pool{
+		Runner:      &Runner{},
+		browser:     browser,
+		cancelAlloc: cancelAlloc,
+		cancel:      cancel,
+		tabs:        make(chan struct{}, max(tabs, 1)),
+	}, nil
+}
+
+// Run espera uma aba livre e executa o cenário nela. A aba é fechada no
+// fim; cancelar ctx ou estourar Timeout interrompe o passo em andamento.
+func (p *Pool) Run(ctx context.Context, sc Scenario) ([]Record, error) {
+	select {
+	case p.tabs <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	defer func() { <-p.tabs }()
+
+	tab, closeTab := chromedp.NewContext(p.browser)
+	defer closeTab()
+	// A aba é criada no primeiro Run; fazer isso fora do contexto com
+	// timeout evita que o timeout encerre o alvo em vez de só o passo.
+	if err := chromedp.Run(tab); err != nil {
+		return nil, err
+	}
+
+	jobCtx, cancel := p.jobContext(tab, ctx)
+	defer cancel()
+	return p.Runner.Run(jobCtx, sc)
+}
+
+// jobContext cria o contexto de um cenário a partir do contexto da aba, com
+// o prazo Timeout. A aba deriva do navegador, não de ctx, então o
+// cancelamento de ctx é repassado à mão.
+func (p *Pool) jobContext(tab, ctx context.Context) (context.Context, context.CancelFunc) {
+	var (
+		jobCtx context.Context
+		cancel context.CancelFunc
+	)
+	if p.Timeout > 0 {
+		jobCtx, cancel = context.WithTimeout(tab, p.Timeout)
+	} else {
+		jobCtx, cancel = context.WithCancel(tab)
+	}
+	stop := context.AfterFunc(ctx, cancel)
+	return jobCtx, func() {
+		stop()
+		cancel()
+	}
+}
+
+// Close fecha o navegador e encerra o processo do Chrome.
+func (p *Pool) Close() {
+	p.cancel()
+	p.cancelAlloc()
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// Estes testes não abrem o navegador: cobrem as repetições do Runner e os
+// prazos do Pool, que não dependem do Chrome.
+
+func TestRunnerRetry(t *testing.T) {
+	tests := []struct {
+		name     string
+		retries  int
+		failures int // tentativas que falham antes do sucesso
+		calls    int
+		fails    bool
+	}{
+		{"de primeira", 2, 0, 1, false},
+		{"depois de falhar", 2, 2, 3, false},
+		{"tentativas esgotadas", 2, 5, 3, true},
+		{"sem repetição", 0, 1, 1, true},
+	}
+	for _, tt := range tests {
+		r := &Runner{Retries: tt.retries, RetryDelay: time.Millisecond}
+		calls := 0
+		err := r.retry(context.Background(), "abrir teste", func() error {
+			if calls++; calls <= tt.failures {
+				return errors.New("recusado")
+			}
+			return nil
+		})
+		if (err != nil) != tt.fails || calls != tt.calls {
+			t.Errorf("%s: retry = %v com %d chamadas, quer falha %v com %d", tt.name, err, calls, tt.fails, tt.calls)
+		}
+	}
+}
+
+func TestRunnerRetryDelay(t *testing.T) {
+	// Espera RetryDelay a mais a cada tentativa: 20ms e depois 40ms
+	r := &Runner{Retries: 2, RetryDelay: 20 * time.Millisecond}
+	start := time.Now()
+	r.retry(context.Background(), "abrir teste", func() error { return errors.New("recusado") })
+	if elapsed := time.Since(start); elapsed < 60*time.Millisecond {
+		t.Errorf("retry levou %v, quer ao menos 60ms", elapsed)
+	}
+}
+
+func TestRunnerRetryCanceled(t *testing.T) {
+	r := &Runner{Retries: 3, RetryDelay: time.Hour}
+
+	// Cancelado durante a espera entre as tentativas
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	calls := 0
+	start := time.Now()
+	err := r.retry(ctx, "abrir teste", func() error { calls++; return errors.New("recusado") })
+	if !errors.Is(err, context.DeadlineExceeded) || calls != 1 {
+		t.Errorf("retry = %v com %d chamadas, quer DeadlineExceeded com 1", err, calls)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("retry levou %v após o prazo", elapsed)
+	}
+
+	// Com o contexto já encerrado não há nova tentativa, e o erro é o dela
+	done, cancel := context.WithCancel(context.Background())
+	cancel()
+	calls = 0
+	failed := errors.New("recusado")
+	if err := r.retry(done, "abrir teste", func() error { calls++; return failed }); err != failed || calls != 1 {
+		t.Errorf("retry cancelado = %v com %d chamadas, quer o erro da tentativa com 1", err, calls)
+	}
+}
+
+func TestPoolJobContext(t *testing.T) {
+	tests := []struct {
+		name    string
+		timeout time.Duration
+		cancel  string // quem encerra: "ctx", "aba" ou ninguém
+		err     error  // nil se o contexto continuar aberto
+	}{
+		{"timeout", 50 * time.Millisecond, "", context.DeadlineExceeded},
+		{"sem timeout", 0, "", nil},
+		{"ctx cancelado", time.Hour, "ctx", context.Canceled},
+		{"ctx cancelado sem timeout", 0, "ctx", context.Canceled},
+		{"aba fechada", time.Hour, "aba", context.Canceled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tab, closeTab := context.WithCancel(context.Background())
+			defer closeTab()
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			p := &Pool{Timeout: tt.timeout}
+			job, done := p.jobContext(tab, ctx)
+			defer done()
+			switch tt.cancel {
+			case "ctx":
+				cancel()
+			case "aba":
+				closeTab()
+			}
+
+			select {
+			case <-job.Done():
+			case <-time.After(200 * time.Millisecond):
+			}
+			if err := job.Err(); !errors.Is(err, tt.err) {
+				t.Errorf("Err = %v, quer %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestPoolRunWaitsForTab(t *testing.T) {
+	// Com a única aba ocupada, Run espera até ctx acabar sem usar o navegador
+	p := &Pool{tabs: make(chan struct{}, 1)}
+	p.tabs <- struct{}{}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	records, err := p.Run(ctx, Scenario{})
+	if !errors.Is(err, context.DeadlineExceeded) || records != nil {
+		t.Errorf("Run = %v, %v; quer DeadlineExceeded", records, err)
+	}
+	if len(p.tabs) != 1 {
+		t.Errorf("abas ocupadas = %d, quer 1", len(p.tabs))
+	}
+}
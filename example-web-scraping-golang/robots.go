@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Robots são as regras do robots.txt de um host que valem para o nosso
+// user-agent.
+type Robots struct {
+	rules      []robotsRule
+	CrawlDelay time.Duration
+}
+
+type robotsRule struct {
+	allow   bool
+	length  int // tamanho do padrão, usado para escolher a regra mais específica
+	pattern *regexp.Regexp
+}
+
+// AllowAll é usado quando o host não tem robots.txt.
+var AllowAll = &Robots{}
+
+// DisallowAll é usado quando o robots.txt não pôde ser lido por erro do
+// servidor: na dúvida, nada é baixado.
+var DisallowAll = &Robots{rules: []robotsRule{{length: 1, pattern: regexp.MustCompile("^/")}}}
+
+// ParseRobots lê um robots.txt e guarda o grupo do agent (comparado sem
+// diferenciar maiúsculas, por prefixo do nome) ou, se não houver, o grupo "*".
+func ParseRobots(r io.Reader, agent string) *Robots {
+	agent = strings.ToLower(agent)
+
+	var (
+		specific, wildcard *Robots
+		current            []*Robots // grupos que recebem as regras lidas
+		groupHasRules      bool
+	)
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line, _, _ := strings.Cut(sc.Text(), "#")
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			// Linhas user-agent seguidas formam um único grupo.
+			if groupHasRules {
+				current = nil
+				groupHasRules = false
+			}
+			name := strings.ToLower(value)
+			switch {
+			case name == "*":
+				if wildcard == nil {
+					wildcard = &Robots{}
+				}
+				current = append(current, wildcard)
+			case name != "" && specific == nil && strings.HasPrefix(agent, name):
+				specific = &Robots{}
+				current = append(current, specific)
+			}
+
+		case "allow", "disallow":
+			groupHasRules = true
+			if value == "" {
+				continue // "Disallow:" vazio libera tudo
+			}
+			rule := robotsRule{allow: key == "allow", length: len(value), pattern: robotsPattern(value)}
+			for _, g := range current {
+				g.rules = append(g.rules, rule)
+			}
+
+		case "crawl-delay":
+			groupHasRules = true
+			if secs, err := strconv.ParseFloat(value, 64); err == nil && secs > 0 {
+				for _, g := range current {
+					g.CrawlDelay = time.Duration(secs * float64(time.Second))
+				}
+			}
+		}
+	}
+
+	switch {
+	case specific != nil:
+		return specific
+	case wildcard != nil:
+		return wildcard
+	default:
+		return AllowAll
+	}
+}
+
+// robotsPattern converte um caminho do robots.txt, com * e $ opcionais, em
+// uma expressão ancorada no início.
+func robotsPattern(path string) *regexp.Regexp {
+	end := strings.HasSuffix(path, "$")
+	path = strings.TrimSuffix(path, "$")
+	expr := "^" + strings.ReplaceAll(regexp.QuoteMeta(path), `\*`, ".*")
+	if end {
+		expr += "$"
+	}
+	return regexp.MustCompile(expr)
+}
+
+// Allowed informa se o caminho (com a query) pode ser baixado. Vale a regra
+// mais longa que casar; em empate, Allow vence.
+func (r *Robots) Allowed(path string) bool {
+	best := robotsRule{allow: true}
+	for _, rule := range r.rules {
+		if !rule.pattern.MatchString(path) {
+			continue
+		}
+		if rule.length > best.length || (rule.length == best.length && rule.allow) {
+			best = rule
+		}
+	}
+	return best.allow
+}
@@ -0,0 +1,278 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/dom"
+	"github.com/chromedp/chromedp"
+)
+
+// Record guarda os campos extraídos de uma página. Os valores são string,
+// []string ou []map[string]string, conforme o tipo de extração.
+type Record map[string]any
+
+// Runner executa cenários em uma aba do navegador. Falhas de navegação são
+// repetidas até Retries vezes, esperando RetryDelay a mais a cada tentativa.
+// NextWait limita a espera pela página seguinte depois do clique em Next;
+// zero usa 10s.
+type Runner struct {
+	Retries    int
+	RetryDelay time.Duration
+	NextWait   time.Duration
+}
+
+// Run executa os passos em ordem no contexto do chromedp (criado com
+// chromedp.NewContext) e retorna os registros extraídos: um por página do
+// passo paginate ou, sem ele, um único registro. Campos extraídos fora do
+// paginate são copiados para todos os registros. Para no primeiro passo que
+// falhar, retornando o que já foi extraído.
+func (r *Runner) Run(ctx context.Context, sc Scenario) ([]Record, error) {
+	base := Record{}
+	var pages []Record
+	var err error
+	for i, step := range sc.Steps {
+		if step.Action == ActionPaginate {
+			pages, err = r.paginate(ctx, step)
+		} else {
+			err = r.runStep(ctx, step, base)
+		}
+		if err != nil {
+			err = fmt.Errorf("passo %d (%s): %w", i+1, step.Action, err)
+			break
+		}
+	}
+
+	if pages == nil {
+		if err != nil && len(base) == 0 {
+			return nil, err
+		}
+		return []Record{base}, err
+	}
+	for _, page := range pages {
+		for k, v := range base {
+			if _, ok := page[k]; !ok {
+				page[k] = v
+			}
+		}
+	}
+	return pages, err
+}
+
+// paginate roda os passos em cada página, gerando um registro por página, e
+// clica em Next até ele não existir mais ou até chegar a MaxPages.
+func (r *Runner) paginate(ctx context.Context, s Step) ([]Record, error) {
+	var records []Record
+	for page := 1; ; page++ {
+		record := Record{}
+		for i, step := range s.Steps {
+			if err := r.runStep(ctx, step, record); err != nil {
+				return records, fmt.Errorf("página %d, passo %d (%s): %w", page, i+1, step.Action, err)
+			}
+		}
+		records = append(records, record)
+
+		if s.MaxPages > 0 && page >= s.MaxPages {
+			return records, nil
+		}
+		var next []*cdp.Node
+		if err := chromedp.Run(ctx, chromedp.Nodes(s.Next, &next, s.queryAll(), chromedp.AtLeast(0))); err != nil {
+			return records, err
+		}
+		if len(next) == 0 {
+			return records, nil
+		}
+		changed, err := r.clickNext(ctx, next[0], s.watched())
+		if err != nil {
+			return records, fmt.Errorf("página %d: %w", page, err)
+		}
+		if !changed {
+			log.Printf("página %d: a página não mudou depois do clique em %q; paginação encerrada", page, s.Next)
+			return records, nil
+		}
+	}
+}
+
+// staleMark marca o botão de próxima página clicado por paginate.
+const staleMark = "data-scraper-clicado"
+
+// clickNext clica no botão de próxima página e espera a troca de página.
+// Sem essa espera a extração seguinte poderia ler a página antiga outra vez,
+// já que os seletores dela (por exemplo, o wait_visible da lista) continuam
+// valendo até a troca.
+//
+// A troca é percebida de duas formas: o botão sai do documento, porque o
+// clique abriu outra página ou o site renderizou tudo de novo, ou muda o
+// primeiro elemento de watch, como em SPAs que mantêm o botão e trocam só a
+// lista. Retorna falso se nada mudar em NextWait.
+func (r *Runner) clickNext(ctx context.Context, next *cdp.Node, watch *Step) (bool, error) {
+	var before pageMark
+	if watch != nil {
+		var err error
+		if before, err = markPage(ctx, *watch); err != nil {
+			return false, err
+		}
+	}
+	err := chromedp.Run(ctx,
+		dom.SetAttributeValue(next.NodeID, staleMark, "1"),
+		chromedp.MouseClickNode(next),
+	)
+	if err != nil {
+		return false, err
+	}
+
+	wait := r.NextWait
+	if wait <= 0 {
+		wait = 10 * time.Second
+	}
+	deadline := time.Now().Add(wait)
+	for {
+		// Erros aqui costumam ser da página em transição; a próxima volta
+		// tenta de novo
+		var marked []*cdp.Node
+		err := chromedp.Run(ctx, chromedp.Nodes("["+staleMark+"]", &marked, chromedp.ByQueryAll, chromedp.AtLeast(0)))
+		if err == nil && len(marked) == 0 {
+			return true, chromedp.Run(ctx, chromedp.WaitReady("body", chromedp.ByQuery))
+		}
+		if watch != nil {
+			// Lista vazia pode ser a nova ainda carregando: só conta a
+			// troca quando ela aparece
+			after, err := markPage(ctx, *watch)
+			if err == nil && after.node != 0 && after != before {
+				return true, nil
+			}
+		}
+		if time.Now().After(deadline) {
+			return false, nil
+		}
+		select {
+		case <-time.After(100 * time.Millisecond):
+		case <-ctx.Done():
+			return false, ctx.Err()
+		}
+	}
+}
+
+// pageMark identifica a página pelo primeiro elemento de um seletor: o nó e
+// o seu texto. Um nó novo ou um texto diferente indicam outra página.
+type pageMark struct {
+	node cdp.NodeID
+	text string
+}
+
+const textJS = `function() { return this.textContent; }`
+
+func markPage(ctx context.Context, watch Step) (pageMark, error) {
+	var nodes []*cdp.Node
+	if err := chromedp.Run(ctx, chromedp.Nodes(watch.Selector, &nodes, watch.queryAll(), chromedp.AtLeast(0))); err != nil {
+		return pageMark{}, err
+	}
+	if len(nodes) == 0 {
+		return pageMark{}, nil
+	}
+	m := pageMark{node: nodes[0].NodeID}
+	err := chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		return callOnNode(ctx, nodes[0], textJS, &m.text)
+	}))
+	return m, err
+}
+
+// watched retorna o passo cujo seletor acompanha a troca de página no
+// paginate: o primeiro extract de lista ou tabela ou, sem eles, o primeiro
+// extract.
+func (s Step) watched() *Step {
+	var first *Step
+	for i := range s.Steps {
+		sub := &s.Steps[i]
+		if sub.Action != ActionExtract {
+			continue
+		}
+		if sub.Kind == KindList || sub.Kind == KindTable {
+			return sub
+		}
+		if first == nil {
+			first = sub
+		}
+	}
+	return first
+}
+
+func (r *Runner) runStep(ctx context.Context, s Step, record Record) error {
+	switch s.Action {
+	case ActionNavigate:
+		return r.navigate(ctx, s.URL)
+
+	case ActionWaitVisible:
+		return chromedp.Run(ctx, chromedp.WaitVisible(s.Selector, s.query()))
+
+	case ActionWaitGone:
+		return chromedp.Run(ctx, chromedp.WaitNotPresent(s.Selector, s.query()))
+
+	case ActionType:
+		return chromedp.Run(ctx,
+			chromedp.WaitVisible(s.Selector, s.query()),
+			chromedp.SendKeys(s.Selector, s.Text, s.query()),
+		)
+
+	case ActionClick:
+		return chromedp.Run(ctx, chromedp.Click(s.Selector, s.query()))
+
+	case ActionExtract:
+		value, err := extract(ctx, s)
+		if err != nil {
+			return err
+		}
+		// Lista vazia fica de fora do registro, como no modo estático
+		if value != nil {
+			record[s.Field] = value
+		}
+		return nil
+
+	case ActionScreenshot:
+		var buf []byte
+		action := chromedp.FullScreenshot(&buf, 90)
+		if s.Selector != "" {
+			action = chromedp.Screenshot(s.Selector, &buf, s.query())
+		}
+		if err := chromedp.Run(ctx, action); err != nil {
+			return err
+		}
+		return os.WriteFile(s.File, buf, 0644)
+
+	default:
+		return fmt.Errorf("ação desconhecida %q", s.Action)
+	}
+}
+
+// navigate abre a URL e espera o body, repetindo em caso de falha. Não repete
+// se o contexto já tiver sido cancelado ou estourado o tempo.
+func (r *Runner) navigate(ctx context.Context, url string) error {
+	return r.retry(ctx, "abrir "+url, func() error {
+		return chromedp.Run(ctx,
+			chromedp.Navigate(url),
+			chromedp.WaitReady("body", chromedp.ByQuery),
+		)
+	})
+}
+
+// retry chama try até ele dar certo, até Retries repetições ou até ctx
+// acabar, esperando RetryDelay, 2*RetryDelay... entre as tentativas.
+func (r *Runner) retry(ctx context.Context, what string, try func() error) error {
+	for attempt := 0; ; attempt++ {
+		err := try()
+		if err == nil || attempt >= r.Retries || ctx.Err() != nil {
+			return err
+		}
+		log.Printf("falha ao %s (tentativa %d de %d): %v", what, attempt+1, r.Retries+1, err)
+
+		select {
+		case <-time.After(r.RetryDelay * time.Duration(attempt+1)):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
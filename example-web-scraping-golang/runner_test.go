@@ -0,0 +1,213 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/chromedp/chromedp"
+)
+
+// testPool inicia o navegador headless ou pula o teste se ele não estiver
+// disponível. CHROME indica o executável, quando não está no PATH.
+func testPool(t *testing.T) *Pool {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("precisa do Chrome")
+	}
+	options := append(chromedp.DefaultExecAllocatorOptions[:], chromedp.NoSandbox)
+	if chrome := os.Getenv("CHROME"); chrome != "" {
+		options = append(options, chromedp.ExecPath(chrome))
+	}
+	pool, err := NewPool(1, options...)
+	if err != nil {
+		t.Skipf("Chrome indisponível: %v", err)
+	}
+	t.Cleanup(pool.Close)
+	pool.Timeout = 30 * time.Second
+	return pool
+}
+
+// catalogo serve três páginas de livros ligadas por "próxima". Em /spa as
+// páginas são trocadas por JavaScript, sem navegação, e só depois de um
+// atraso, como em sites que carregam a lista por fetch.
+func catalogo() *httptest.Server {
+	page := func(n int) string {
+		next := ""
+		if n < 3 {
+			next = fmt.Sprintf(`<li class="next"><a href="/pagina/%d">próxima</a></li>`, n+1)
+		}
+		return fmt.Sprintf(`<h1>Catálogo</h1>
+<ol class="row"><li><a title="Livro %[1]d-a">a</a></li><li><a title="Livro %[1]d-b">b</a></li></ol>
+<ul class="pager"><li class="current">Página %[1]d de 3</li>%[2]s</ul>`, n, next)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /pagina/{n}", func(w http.ResponseWriter, r *http.Request) {
+		var n int
+		fmt.Sscan(r.PathValue("n"), &n)
+		if n < 1 || n > 3 {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintf(w, "<!doctype html><html><body>%s</body></html>", page(n))
+	})
+	mux.HandleFunc("GET /spa", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `<!doctype html><html><body><div id="app">%s</div><script>
+const pages = [null, %q, %q, %q];
+document.addEventListener("click", e => {
+	const a = e.target.closest("li.next a");
+	if (!a) return;
+	e.preventDefault();
+	const n = Number(a.getAttribute("href").split("/").pop());
+	setTimeout(() => { document.getElementById("app").innerHTML = pages[n]; }, 300);
+});
+</script></body></html>`, page(1), page(1), page(2), page(3))
+	})
+	// Em /spa-lista o botão é o mesmo nó o tempo todo: só a lista e o
+	// indicador de página são trocados, e o link passa a apontar adiante
+	mux.HandleFunc("GET /spa-lista", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `<!doctype html><html><body>%s<script>
+document.addEventListener("click", e => {
+	const a = e.target.closest("li.next a");
+	if (!a) return;
+	e.preventDefault();
+	const n = Number(a.getAttribute("href").split("/").pop());
+	setTimeout(() => {
+		document.querySelector("ol.row").innerHTML = `+"`"+`<li><a title="Livro ${n}-a">a</a></li><li><a title="Livro ${n}-b">b</a></li>`+"`"+`;
+		document.querySelector("li.current").textContent = `+"`"+`Página ${n} de 3`+"`"+`;
+		if (n < 3) a.setAttribute("href", "/pagina/" + (n + 1));
+		else a.parentNode.remove();
+	}, 300);
+});
+</script></body></html>`, page(1))
+	})
+	// Em /parado o botão de próxima não faz nada
+	mux.HandleFunc("GET /parado", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `<!doctype html><html><body>%s<script>
+document.addEventListener("click", e => e.preventDefault());
+</script></body></html>`, page(1))
+	})
+	return httptest.NewServer(mux)
+}
+
+func livrosScenario(url string) Scenario {
+	return Scenario{
+		Name: "livros",
+		Steps: []Step{
+			{Action: ActionNavigate, URL: url},
+			{Action: ActionExtract, Selector: "h1", Field: "titulo"},
+			{Action: ActionPaginate, Next: "li.next > a", Steps: []Step{
+				{Action: ActionWaitVisible, Selector: "ol.row"},
+				{Action: ActionExtract, Selector: "li.current", Field: "pagina"},
+				{Action: ActionExtract, Selector: "ol.row a", Kind: KindList, Attr: "title", Field: "livros"},
+			}},
+		},
+	}
+}
+
+func TestRunnerPaginate(t *testing.T) {
+	pool := testPool(t)
+	srv := catalogo()
+	defer srv.Close()
+
+	want := []Record{
+		{"titulo": "Catálogo", "pagina": "Página 1 de 3", "livros": []string{"Livro 1-a", "Livro 1-b"}},
+		{"titulo": "Catálogo", "pagina": "Página 2 de 3", "livros": []string{"Livro 2-a", "Livro 2-b"}},
+		{"titulo": "Catálogo", "pagina": "Página 3 de 3", "livros": []string{"Livro 3-a", "Livro 3-b"}},
+	}
+	// Com navegação e com a lista trocada por JavaScript, cada página deve
+	// ser extraída uma única vez
+	for _, path := range []string{"/pagina/1", "/spa", "/spa-lista"} {
+		t.Run(path, func(t *testing.T) {
+			records, err := pool.Run(context.Background(), livrosScenario(srv.URL+path))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(records, want) {
+				t.Errorf("registros =\n%v\nquer\n%v", records, want)
+			}
+		})
+	}
+}
+
+func TestRunnerMaxPages(t *testing.T) {
+	pool := testPool(t)
+	srv := catalogo()
+	defer srv.Close()
+
+	sc := livrosScenario(srv.URL + "/pagina/1")
+	sc.Steps[2].MaxPages = 2
+	records, err := pool.Run(context.Background(), sc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 || records[1]["pagina"] != "Página 2 de 3" {
+		t.Errorf("registros = %v", records)
+	}
+}
+
+func TestRunnerStepError(t *testing.T) {
+	pool := testPool(t)
+	srv := catalogo()
+	defer srv.Close()
+
+	sc := livrosScenario(srv.URL + "/pagina/1")
+	sc.Steps[2].Steps[0] = Step{Action: ActionWaitVisible, Selector: "#nao-existe"}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	// O que foi extraído antes do erro é devolvido junto com ele
+	records, err := pool.Run(ctx, sc)
+	if err == nil {
+		t.Fatal("esperava erro no passo wait_visible")
+	}
+	if len(records) != 1 || records[0]["titulo"] != "Catálogo" {
+		t.Errorf("registros = %v", records)
+	}
+}
+
+func TestRunnerNextUnchanged(t *testing.T) {
+	pool := testPool(t)
+	pool.Runner.NextWait = time.Second
+	srv := catalogo()
+	defer srv.Close()
+
+	// Um clique que não troca a página encerra a paginação sem repetir a
+	// página nem esperar o tempo limite do cenário
+	records, err := pool.Run(context.Background(), livrosScenario(srv.URL+"/parado"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || records[0]["pagina"] != "Página 1 de 3" {
+		t.Errorf("registros = %v", records)
+	}
+}
+
+func TestRunnerEmptyList(t *testing.T) {
+	pool := testPool(t)
+	srv := catalogo()
+	defer srv.Close()
+
+	// Como no modo estático, uma lista sem elementos não espera e fica de
+	// fora do registro
+	sc := Scenario{Steps: []Step{
+		{Action: ActionNavigate, URL: srv.URL + "/pagina/3"},
+		{Action: ActionExtract, Selector: "li.next a", Kind: KindList, Field: "proxima"},
+		{Action: ActionExtract, Selector: "h1", Field: "titulo"},
+	}}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	records, err := pool.Run(ctx, sc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []Record{{"titulo": "Catálogo"}}; !reflect.DeepEqual(records, want) {
+		t.Errorf("registros = %v, quer %v", records, want)
+	}
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Ações aceitas nos passos de um cenário.
+const (
+	ActionNavigate    = "navigate"     // abre URL
+	ActionWaitVisible = "wait_visible" // espera Selector ficar visível
+	ActionWaitGone    = "wait_gone"    // espera Selector sair da página (ex.: "carregando...")
+	ActionType        = "type"         // digita Text em Selector
+	ActionClick       = "click"        // clica em Selector
+	ActionExtract     = "extract"      // guarda o conteúdo de Selector no campo Field
+	ActionScreenshot  = "screenshot"   // salva a página (ou só Selector) em File
+	ActionPaginate    = "paginate"     // repete Steps em cada página, clicando em Next
+)
+
+// Tipos de extração, usados em Step.Kind.
+const (
+	KindText  = "text"  // texto visível do primeiro elemento
+	KindAttr  = "attr"  // atributo Attr do primeiro elemento
+	KindList  = "list"  // texto (ou Attr) de todos os elementos
+	KindTable = "table" // linhas de uma <table>, com a primeira como cabeçalho
+)
+
+// Linguagens de seletor, usadas em Step.By.
+const (
+	ByCSS   = "css"
+	ByXPath = "xpath"
+)
+
+// Scenario é um roteiro de raspagem descrito em YAML ou JSON.
+type Scenario struct {
+	Name  string `yaml:"name"`
+	Steps []Step `yaml:"steps"`
+	Crawl Crawl  `yaml:"crawl,omitempty"`
+}
+
+// Crawl configura o modo estático (sem navegador). As páginas iniciais são as
+// URLs dos passos navigate e os passos extract são aplicados a cada página.
+type Crawl struct {
+	Depth    int           `yaml:"depth,omitempty"`     // níveis de links seguidos a partir das páginas iniciais
+	Follow   string        `yaml:"follow,omitempty"`    // seletor CSS dos links seguidos; padrão "a[href]"
+	Domains  []string      `yaml:"domains,omitempty"`   // domínios permitidos; padrão: os das páginas iniciais
+	Delay    time.Duration `yaml:"delay,omitempty"`     // intervalo mínimo entre requisições ao mesmo host
+	MaxPages int           `yaml:"max_pages,omitempty"` // limite de páginas baixadas; zero não limita
+}
+
+// Step é um passo do cenário. Os campos usados dependem de Action.
+type Step struct {
+	Action   string `yaml:"action"`
+	URL      string `yaml:"url,omitempty"`
+	Selector string `yaml:"selector,omitempty"`
+	By       string `yaml:"by,omitempty"` // css (padrão) ou xpath
+	Text     string `yaml:"text,omitempty"`
+	Field    string `yaml:"field,omitempty"`
+	Kind     string `yaml:"kind,omitempty"` // text (padrão), attr, list ou table
+	Attr     string `yaml:"attr,omitempty"`
+	File     string `yaml:"file,omitempty"`
+
+	// Usados por paginate: Steps roda em cada página e gera um registro;
+	// depois clica em Next até ele não existir mais ou chegar a MaxPages.
+	Next     string `yaml:"next,omitempty"`
+	MaxPages int    `yaml:"max_pages,omitempty"`
+	Steps    []Step `yaml:"steps,omitempty"`
+}
+
+// LoadScenario lê um cenário de um arquivo YAML ou JSON (JSON também é YAML
+// válido). Um campo com nome desconhecido, como "selecter", é erro: ignorado,
+// ele faria o passo falhar só no navegador, ou nem falhar.
+func LoadScenario(path string) (Scenario, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Scenario{}, err
+	}
+	defer f.Close()
+
+	var sc Scenario
+	dec := yaml.NewDecoder(f)
+	dec.KnownFields(true)
+	if err := dec.Decode(&sc); err != nil {
+		return Scenario{}, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := sc.Validate(); err != nil {
+		return Scenario{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return sc, nil
+}
+
+// Validate verifica se cada passo tem os campos exigidos pela sua ação,
+// para que erros no arquivo apareçam antes de abrir o navegador.
+func (sc Scenario) Validate() error {
+	if len(sc.Steps) == 0 {
+		return errors.New("o cenário não tem passos")
+	}
+	paginated := false
+	for i, s := range sc.Steps {
+		if s.Action == ActionPaginate {
+			if paginated {
+				return fmt.Errorf("passo %d (%s): só é permitido um paginate por cenário", i+1, s.Action)
+			}
+			paginated = true
+		}
+		if err := s.validate(); err != nil {
+			return fmt.Errorf("passo %d (%s): %w", i+1, s.Action, err)
+		}
+	}
+	return nil
+}
+
+// Fields retorna os nomes dos campos extraídos, na ordem em que aparecem no
+// cenário. É a ordem das colunas na saída CSV.
+func (sc Scenario) Fields() []string {
+	var fields []string
+	seen := make(map[string]bool)
+	var walk func(steps []Step)
+	walk = func(steps []Step) {
+		for _, s := range steps {
+			if s.Action == ActionExtract && !seen[s.Field] {
+				seen[s.Field] = true
+				fields = append(fields, s.Field)
+			}
+			walk(s.Steps)
+		}
+	}
+	walk(sc.Steps)
+	return fields
+}
+
+// ValidateStatic verifica se o cenário pode rodar sem navegador: precisa de
+// ao menos um navigate e não pode digitar, clicar nem tirar screenshots. As
+// esperas são ignoradas, pois o HTML estático já vem completo.
+func (sc Scenario) ValidateStatic() error {
+	if len(sc.StartURLs()) == 0 {
+		return errors.New("o modo estático precisa de ao menos um passo navigate")
+	}
+	if sc.Crawl.Depth < 0 || sc.Crawl.MaxPages < 0 || sc.Crawl.Delay < 0 {
+		return errors.New("crawl: depth, max_pages e delay não podem ser negativos")
+	}
+	var check func(steps []Step) error
+	check = func(steps []Step) error {
+		for i, s := range steps {
+			switch s.Action {
+			case ActionType, ActionClick, ActionScreenshot:
+				return fmt.Errorf("passo %d (%s): ação não suportada no modo estático", i+1, s.Action)
+			}
+			if err := check(s.Steps); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return check(sc.Steps)
+}
+
+// StartURLs retorna as URLs dos passos navigate, usadas como páginas iniciais
+// no modo estático.
+func (sc Scenario) StartURLs() []string {
+	var urls []string
+	for _, s := range sc.Steps {
+		if s.Action == ActionNavigate {
+			urls = append(urls, s.URL)
+		}
+	}
+	return urls
+}
+
+func (s Step) validate() error {
+	require := func(name, value string) error {
+		if value == "" {
+			return fmt.Errorf("campo %q obrigatório", name)
+		}
+		return nil
+	}
+
+	if s.By != "" && s.By != ByCSS && s.By != ByXPath {
+		return fmt.Errorf("by deve ser %q ou %q, recebido %q", ByCSS, ByXPath, s.By)
+	}
+
+	switch s.Action {
+	case ActionNavigate:
+		return require("url", s.URL)
+	case ActionWaitVisible, ActionWaitGone, ActionClick:
+		return require("selector", s.Selector)
+	case ActionType:
+		return errors.Join(require("selector", s.Selector), require("text", s.Text))
+	case ActionExtract:
+		err := errors.Join(require("selector", s.Selector), require("field", s.Field))
+		switch s.Kind {
+		case "", KindText, KindList, KindTable:
+		case KindAttr:
+			err = errors.Join(err, require("attr", s.Attr))
+		default:
+			err = errors.Join(err, fmt.Errorf("kind desconhecido %q", s.Kind))
+		}
+		return err
+	case ActionScreenshot:
+		return require("file", s.File)
+	case ActionPaginate:
+		if len(s.Steps) == 0 {
+			return errors.New("paginate sem passos")
+		}
+		for i, sub := range s.Steps {
+			if sub.Action == ActionPaginate {
+				return fmt.Errorf("passo %d: paginate não pode ser aninhado", i+1)
+			}
+			if err := sub.validate(); err != nil {
+				return fmt.Errorf("passo %d (%s): %w", i+1, sub.Action, err)
+			}
+		}
+		return require("next", s.Next)
+	default:
+		return fmt.Errorf("ação desconhecida %q", s.Action)
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestScenarioValidate(t *testing.T) {
+	nav := Step{Action: ActionNavigate, URL: "https://exemplo.com"}
+	extract := Step{Action: ActionExtract, Selector: "h1", Field: "titulo"}
+	paginate := Step{Action: ActionPaginate, Next: "a.next", Steps: []Step{extract}}
+	tests := []struct {
+		name  string
+		steps []Step
+		err   string // trecho da mensagem; vazio se válido
+	}{
+		{"válido", []Step{nav, extract, paginate}, ""},
+		{"xpath", []Step{{Action: ActionClick, Selector: "//button", By: ByXPath}}, ""},
+		{"atributo", []Step{{Action: ActionExtract, Selector: "a", Field: "link", Kind: KindAttr, Attr: "href"}}, ""},
+		{"sem passos", nil, "não tem passos"},
+		{"ação desconhecida", []Step{{Action: "scroll"}}, `ação desconhecida "scroll"`},
+		{"navigate sem url", []Step{{Action: ActionNavigate}}, `passo 1 (navigate): campo "url"`},
+		{"wait sem seletor", []Step{nav, {Action: ActionWaitGone}}, `passo 2 (wait_gone): campo "selector"`},
+		{"type sem texto", []Step{{Action: ActionType, Selector: "input"}}, `campo "text"`},
+		{"extract sem campo", []Step{{Action: ActionExtract, Selector: "h1"}}, `campo "field"`},
+		{"attr sem atributo", []Step{{Action: ActionExtract, Selector: "a", Field: "f", Kind: KindAttr}}, `campo "attr"`},
+		{"kind desconhecido", []Step{{Action: ActionExtract, Selector: "a", Field: "f", Kind: "html"}}, `kind desconhecido "html"`},
+		{"by desconhecido", []Step{{Action: ActionClick, Selector: "a", By: "jquery"}}, `by deve ser "css" ou "xpath"`},
+		{"screenshot sem arquivo", []Step{{Action: ActionScreenshot}}, `campo "file"`},
+		{"paginate sem next", []Step{{Action: ActionPaginate, Steps: []Step{extract}}}, `campo "next"`},
+		{"paginate sem passos", []Step{{Action: ActionPaginate, Next: "a.next"}}, "paginate sem passos"},
+		{"paginate com passo inválido", []Step{{Action: ActionPaginate, Next: "a", Steps: []Step{extract, {Action: ActionClick}}}}, `passo 1 (paginate): passo 2 (click)`},
+		{"paginate aninhado", []Step{{Action: ActionPaginate, Next: "a", Steps: []Step{paginate}}}, "não pode ser aninhado"},
+		{"dois paginate", []Step{paginate, paginate}, "só é permitido um paginate"},
+	}
+	for _, tt := range tests {
+		err := Scenario{Steps: tt.steps}.Validate()
+		switch {
+		case tt.err == "" && err != nil:
+			t.Errorf("%s: Validate = %v", tt.name, err)
+		case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+			t.Errorf("%s: Validate = %v, quer erro com %q", tt.name, err, tt.err)
+		}
+	}
+}
+
+func TestLoadScenario(t *testing.T) {
+	tests := []struct {
+		name, data string
+		fails      bool
+	}{
+		{"yaml", "name: t\nsteps:\n  - action: navigate\n    url: https://exemplo.com\n", false},
+		{"json", `{"name": "t", "steps": [{"action": "extract", "selector": "h1", "field": "titulo"}]}`, false},
+		{"campo desconhecido", "steps:\n  - action: click\n    selecter: a\n", true},
+		{"campo desconhecido no paginate", "steps:\n  - action: paginate\n    next: a\n    steps:\n      - {action: extract, selector: h1, field: t, kind: list, atr: href}\n", true},
+		{"crawl desconhecido", "crawl: {deph: 1}\nsteps:\n  - {action: navigate, url: https://exemplo.com}\n", true},
+		{"inválido", "steps:\n  - action: extract\n", true},
+		{"vazio", "", true},
+		{"não é yaml", "steps: [", true},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), "cenario.yaml")
+		if err := os.WriteFile(path, []byte(tt.data), 0644); err != nil {
+			t.Fatal(err)
+		}
+		_, err := LoadScenario(path)
+		if (err != nil) != tt.fails {
+			t.Errorf("%s: LoadScenario = %v, falha esperada: %v", tt.name, err, tt.fails)
+		}
+		if err != nil && !strings.Contains(err.Error(), path) {
+			t.Errorf("%s: erro sem o nome do arquivo: %v", tt.name, err)
+		}
+	}
+
+	if _, err := LoadScenario(filepath.Join(t.TempDir(), "nao-existe.yaml")); err == nil {
+		t.Error("LoadScenario aceitou arquivo inexistente")
+	}
+}
+
+func TestLoadScenarioExamples(t *testing.T) {
+	paths, err := filepath.Glob("cenarios/*.yaml")
+	if err != nil || len(paths) == 0 {
+		t.Fatalf("nenhum cenário de exemplo: %v", err)
+	}
+	for _, path := range paths {
+		if _, err := LoadScenario(path); err != nil {
+			t.Error(err)
+		}
+	}
+
+	sc, err := LoadScenario("cenarios/livros.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sc.ValidateStatic(); err != nil {
+		t.Errorf("livros.yaml no modo estático: %v", err)
+	}
+	want := []string{"categorias", "pagina", "titulos", "precos", "primeiro_link"}
+	if got := sc.Fields(); !slices.Equal(got, want) {
+		t.Errorf("Fields = %v, quer %v", got, want)
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/andybalholm/cascadia"
+	"github.com/antchfx/htmlquery"
+	"golang.org/x/net/html"
+)
+
+// findAll retorna os elementos do documento que casam com o seletor do passo.
+func (s Step) findAll(doc *html.Node) ([]*html.Node, error) {
+	if s.By == ByXPath {
+		return htmlquery.QueryAll(doc, s.Selector)
+	}
+	sel, err := cascadia.Compile(s.Selector)
+	if err != nil {
+		return nil, err
+	}
+	return cascadia.QueryAll(doc, sel), nil
+}
+
+// extractStatic aplica a extração do passo ao HTML já baixado, com os mesmos
+// tipos do modo com navegador. ok é falso quando nenhum elemento casa: ao
+// contrário do navegador, não há o que esperar e o campo fica de fora.
+func extractStatic(doc *html.Node, s Step) (value any, ok bool, err error) {
+	nodes, err := s.findAll(doc)
+	if err != nil || len(nodes) == 0 {
+		return nil, false, err
+	}
+
+	switch s.Kind {
+	case "", KindText:
+		return nodeText(nodes[0]), true, nil
+
+	case KindAttr:
+		return htmlquery.SelectAttr(nodes[0], s.Attr), true, nil
+
+	case KindList:
+		values := make([]string, len(nodes))
+		for i, n := range nodes {
+			if s.Attr != "" {
+				values[i] = strings.TrimSpace(htmlquery.SelectAttr(n, s.Attr))
+			} else {
+				values[i] = nodeText(n)
+			}
+		}
+		return values, true, nil
+
+	case KindTable:
+		return tableRows(tableCells(nodes[0])), true, nil
+
+	default:
+		return nil, false, fmt.Errorf("kind desconhecido %q", s.Kind)
+	}
+}
+
+var (
+	rowSelector  = cascadia.MustCompile("tr")
+	cellSelector = cascadia.MustCompile("th, td")
+)
+
+// tableCells monta a mesma matriz de textos que tableJS retorna no navegador.
+func tableCells(table *html.Node) [][]string {
+	var cells [][]string
+	for _, row := range cascadia.QueryAll(table, rowSelector) {
+		var line []string
+		for _, cell := range cascadia.QueryAll(row, cellSelector) {
+			line = append(line, nodeText(cell))
+		}
+		cells = append(cells, line)
+	}
+	return cells
+}
+
+// blockElements são separados por espaço ao juntar o texto, como as quebras
+// de linha do innerText.
+var blockElements = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true, "br": true,
+	"dd": true, "div": true, "dl": true, "dt": true, "footer": true, "h1": true,
+	"h2": true, "h3": true, "h4": true, "h5": true, "h6": true, "header": true,
+	"hr": true, "li": true, "main": true, "nav": true, "ol": true, "p": true,
+	"pre": true, "section": true, "table": true, "td": true, "th": true,
+	"tr": true, "ul": true,
+}
+
+// nodeText junta o texto do elemento, ignorando scripts e estilos, com os
+// espaços normalizados. É uma aproximação do innerText do navegador.
+func nodeText(n *html.Node) string {
+	var sb strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		switch n.Type {
+		case html.TextNode:
+			sb.WriteString(n.Data)
+			return
+		case html.ElementNode:
+			switch n.Data {
+			case "script", "style", "noscript", "template":
+				return
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+		if n.Type == html.ElementNode && blockElements[n.Data] {
+			sb.WriteByte(' ')
+		}
+	}
+	walk(n)
+	return strings.Join(strings.Fields(sb.String()), " ")
+}